@@ -0,0 +1,187 @@
+package oss
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// uploadCheckpoint defined the progress of resumable upload saved in the checkpoint file
+type uploadCheckpoint struct {
+	Bucket   string
+	Object   string
+	FilePath string
+	FileSize int64
+	ModTime  time.Time
+	PartSize int64
+	UploadID string
+	// part number to ETag of the uploaded parts
+	Parts map[int]string
+
+	locker sync.Mutex
+	path   string
+}
+
+// saveCheckpoint write the checkpoint into a temporary file and rename it, so a crash never break the file
+func saveCheckpoint(path string, cp interface{}) error {
+	var data, err = json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	var tmpPath = path + ".tmp"
+	if err = ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// loadCheckpoint read the checkpoint file into cp
+func loadCheckpoint(path string, cp interface{}) error {
+	var data, err = ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, cp)
+}
+
+// loadUploadCheckpoint load the checkpoint file, nil if it not exists or is broken
+func loadUploadCheckpoint(path string) *uploadCheckpoint {
+	var cp = new(uploadCheckpoint)
+	if err := loadCheckpoint(path, cp); err != nil {
+		return nil
+	}
+	cp.path = path
+	return cp
+}
+
+// isValid check the checkpoint is for the same upload and the file is not modified
+func (cp *uploadCheckpoint) isValid(bucket, object, filePath string, stat os.FileInfo, partSize int64) bool {
+	return cp.UploadID != "" && cp.Bucket == bucket && cp.Object == object && cp.FilePath == filePath &&
+		cp.FileSize == stat.Size() && cp.ModTime.Equal(stat.ModTime()) && cp.PartSize == partSize
+}
+
+// save write the checkpoint file
+func (cp *uploadCheckpoint) save() error {
+	return saveCheckpoint(cp.path, cp)
+}
+
+// addPart record the uploaded part and save the checkpoint
+func (cp *uploadCheckpoint) addPart(part Part) error {
+	cp.locker.Lock()
+	defer cp.locker.Unlock()
+	cp.Parts[part.PartNumber] = part.ETag
+	return cp.save()
+}
+
+// listAllParts list all the uploaded parts of the multipart upload
+func (multi *MultipartUpload) listAllParts(ctx context.Context) ([]Part, error) {
+	var parts []Part
+	var marker int
+	for {
+		var result ListPartsResult
+		if err := multi.ListPartsWithContext(ctx, 1000, marker, &result); err != nil {
+			return nil, err
+		}
+		parts = append(parts, result.Parts...)
+		if !result.IsTruncated || result.NextPartNumberMarker <= marker {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
+// resumeUpload NOT public API
+// Upload the file with the checkpoint of opts, the uploaded parts are skipped.
+// When it failed the multipart upload is kept, so it can resume next time.
+func (api *API) resumeUpload(ctx context.Context, bucket, object string, fp *os.File, stat os.FileInfo,
+	opts *UploadOptions) (result CompleteMultipartUploadResult, err error) {
+
+	var filePath string
+	if filePath, err = filepath.Abs(fp.Name()); err != nil {
+		return
+	}
+	var size = stat.Size()
+	var partSize = getPartSize(size, opts.PartSize)
+
+	var progress = newProgressTracker(getProgressListener(ctx, opts.Progress), size)
+	defer func() {
+		progress.finish(err)
+	}()
+
+	var multi *MultipartUpload
+	var done = make(map[int]Part)
+	var cp = loadUploadCheckpoint(opts.Checkpoint)
+	if cp != nil && !cp.isValid(bucket, object, filePath, stat, partSize) {
+		// the file or options are changed, the old parts are useless
+		if cp.UploadID != "" {
+			var old, _ = api.GetMultiPartUpload(cp.Bucket, cp.Object, cp.UploadID)
+			old.AbortUploadWithContext(ctx)
+		}
+		cp = nil
+	}
+	if cp != nil {
+		multi, _ = api.GetMultiPartUpload(bucket, object, cp.UploadID)
+		var parts []Part
+		if parts, err = multi.listAllParts(ctx); err != nil {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			// the upload may be aborted or expired, start a new one
+			err = nil
+			cp = nil
+		}
+		for _, part := range parts {
+			var length = partSize
+			if offset := int64(part.PartNumber-1) * partSize; offset+length > size {
+				length = size - offset
+			}
+			// only the parts both recorded and uploaded with the same size are skipped
+			if etag, ok := cp.Parts[part.PartNumber]; ok && etag == part.ETag && int64(part.Size) == length {
+				done[part.PartNumber] = Part{PartNumber: part.PartNumber, ETag: part.ETag}
+				progress.skip(length)
+			}
+		}
+	}
+
+	if cp == nil {
+		if multi, err = api.NewMultipartUploadWithContext(ctx, bucket, object, copyMap(opts.Headers)); err != nil {
+			return
+		}
+		cp = &uploadCheckpoint{
+			Bucket:   bucket,
+			Object:   object,
+			FilePath: filePath,
+			FileSize: size,
+			ModTime:  stat.ModTime(),
+			PartSize: partSize,
+			UploadID: multi.UploadID,
+			path:     opts.Checkpoint,
+		}
+	}
+	cp.Parts = make(map[int]string)
+	for partNumber, part := range done {
+		cp.Parts[partNumber] = part.ETag
+	}
+	if err = cp.save(); err != nil {
+		return
+	}
+	progress.started()
+
+	// the checkpoint is saved after each part, if it failed the part is uploaded again next time
+	multi.TrafficLimit = opts.TrafficLimit
+	var parts []Part
+	if parts, err = multi.uploadParts(ctx, fp, size, partSize, opts.Concurrency, done, func(part Part) {
+		cp.addPart(part)
+	}, progress); err != nil {
+		return
+	}
+	if err = multi.CompleteUploadWithContext(ctx, parts, &result); err != nil {
+		return
+	}
+	os.Remove(opts.Checkpoint)
+	return
+}
@@ -0,0 +1,98 @@
+package oss
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResumeUpload(t *testing.T) {
+	var server = &multipartTestServer{parts: make(map[int][]byte)}
+	server.failPart = 3
+	var ts = httptest.NewServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var uploadAPI, _ = NewAPI(options)
+
+	var dir, err = ioutil.TempDir("", "oss-go-sdk-checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var fileName = filepath.Join(dir, "file")
+	var data = bytes.Repeat([]byte("0123456789"), MinPartSize/2)
+	ioutil.WriteFile(fileName, data, 0600)
+
+	var opts = GetDefaultUploadOptions()
+	opts.PartSize = MinPartSize
+	opts.Concurrency = 1
+	opts.Checkpoint = filepath.Join(dir, "file.cp")
+	if _, err = uploadAPI.UploadFile("bucket", "object", fileName, opts); err == nil {
+		t.Fatalf("UploadFile: except: AccessDenied, but got: nil\n")
+	}
+	if server.aborted {
+		t.Fatalf("UploadFile: the resumable upload is aborted\n")
+	}
+	var cp = loadUploadCheckpoint(opts.Checkpoint)
+	if cp == nil || cp.UploadID != "upload-id" || len(cp.Parts) != 2 {
+		t.Fatalf("UploadFile: got unexcept checkpoint: %+v\n", cp)
+	}
+
+	server.failPart = 0
+	server.uploaded = 0
+	if _, err = uploadAPI.UploadFile("bucket", "object", fileName, opts); err != nil {
+		t.Fatalf("UploadFile: except: nil, but got: %s\n", err)
+	}
+	if server.uploaded != 3 {
+		t.Fatalf("UploadFile: except: %d parts uploaded, but got: %d\n", 3, server.uploaded)
+	}
+	if !bytes.Equal(server.object, data) {
+		t.Fatalf("UploadFile: the object is not equal to the data\n")
+	}
+	if _, err = os.Stat(opts.Checkpoint); !os.IsNotExist(err) {
+		t.Fatalf("UploadFile: the checkpoint is not removed\n")
+	}
+}
+
+func TestResumeUploadFileChanged(t *testing.T) {
+	var server = &multipartTestServer{parts: make(map[int][]byte)}
+	server.failPart = 2
+	var ts = httptest.NewServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var uploadAPI, _ = NewAPI(options)
+
+	var dir, err = ioutil.TempDir("", "oss-go-sdk-checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var fileName = filepath.Join(dir, "file")
+	var data = bytes.Repeat([]byte("0123456789"), MinPartSize/2)
+	ioutil.WriteFile(fileName, data, 0600)
+
+	var opts = GetDefaultUploadOptions()
+	opts.PartSize = MinPartSize
+	opts.Concurrency = 1
+	opts.Checkpoint = filepath.Join(dir, "file.cp")
+	uploadAPI.UploadFile("bucket", "object", fileName, opts)
+
+	var modTime = time.Now().Add(time.Hour)
+	os.Chtimes(fileName, modTime, modTime)
+	server.failPart = 0
+	server.uploaded = 0
+	if _, err = uploadAPI.UploadFile("bucket", "object", fileName, opts); err != nil {
+		t.Fatalf("UploadFile: except: nil, but got: %s\n", err)
+	}
+	if !server.aborted || server.uploaded != 5 {
+		t.Fatalf("UploadFile: except the old upload aborted and 5 parts uploaded, but got: %v %d\n",
+			server.aborted, server.uploaded)
+	}
+}
@@ -0,0 +1,115 @@
+package oss
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// crc64Table the ECMA table OSS used to calculate x-oss-hash-crc64ecma
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// ErrCRC64Mismatch returned when the CRC64 of uploaded data is not equal to the one returned by OSS
+var ErrCRC64Mismatch = errors.New("oss: crc64 mismatch")
+
+// crc64Reader calculate the CRC64 of data while it is read,
+// the CRC64 is reset when it is rewound for retry.
+type crc64Reader struct {
+	reader io.Reader
+	hash   hash.Hash64
+}
+
+func newCRC64Reader(reader io.Reader) *crc64Reader {
+	return &crc64Reader{
+		reader: reader,
+		hash:   crc64.New(crc64Table),
+	}
+}
+
+func (r *crc64Reader) Read(p []byte) (int, error) {
+	var n, err = r.reader.Read(p)
+	r.hash.Write(p[:n])
+	return n, err
+}
+
+// Seek seek the underlying reader, only seek to where it starts is expected.
+func (r *crc64Reader) Seek(offset int64, whence int) (int64, error) {
+	var seeker, ok = r.reader.(io.Seeker)
+	if !ok {
+		return 0, errors.New("oss: body is not seekable")
+	}
+	if offset != 0 || whence != io.SeekCurrent {
+		r.hash.Reset()
+	}
+	return seeker.Seek(offset, whence)
+}
+
+// checkCRC64 compare the CRC64 of client with the x-oss-hash-crc64ecma header
+func checkCRC64(crc hash.Hash64, header http.Header) error {
+	var value = header.Get("x-oss-hash-crc64ecma")
+	if crc == nil || value == "" {
+		return nil
+	}
+	var server, err = strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return err
+	}
+	if crc.Sum64() != server {
+		return fmt.Errorf("%w: client: %d, server: %d", ErrCRC64Mismatch, crc.Sum64(), server)
+	}
+	return nil
+}
+
+// prepareBody NOT public API
+// Set the body of upload request.
+// With api.enableCRC64 the body is streamed and the CRC64 hash is returned to check the response,
+// otherwise the Content-MD5 is calculated ahead, the body is read into memory if it can not seek.
+func (api *API) prepareBody(options *requestOptions, body io.Reader) (hash.Hash64, error) {
+	if body == nil {
+		return nil, nil
+	}
+	if value := safeGetElement("Content-Length", options.Headers); value != "" {
+		var length, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		options.ContentLength = length
+	} else if length, ok := getReaderLength(body); ok {
+		options.ContentLength = length
+	}
+
+	if api.enableCRC64 {
+		var reader = newCRC64Reader(body)
+		options.Body = reader
+		return reader.hash, nil
+	}
+
+	if safeGetElement("Content-MD5", options.Headers) != "" {
+		options.Body = body
+		return nil, nil
+	}
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			options.Headers["Content-MD5"] = getBase64MD5WithReader(seeker)
+			if _, err = seeker.Seek(offset, io.SeekStart); err != nil {
+				return nil, err
+			}
+			options.Body = seeker
+			return nil, nil
+		}
+	}
+	var data, err = ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	options.Headers["Content-MD5"] = getBase64MD5(data)
+	options.ContentLength = int64(len(data))
+	options.Body = bytes.NewReader(data)
+	return nil, nil
+}
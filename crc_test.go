@@ -0,0 +1,159 @@
+package oss
+
+import (
+	"errors"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCRC64Reader(t *testing.T) {
+	var reader = newCRC64Reader(strings.NewReader("hello world"))
+	ioutil.ReadAll(reader)
+	var except = crc64.Checksum([]byte("hello world"), crc64Table)
+	if reader.hash.Sum64() != except {
+		t.Fatalf("crc64Reader: except: %d, but got: %d\n", except, reader.hash.Sum64())
+	}
+	// rewind for retry
+	reader.Seek(0, io.SeekStart)
+	ioutil.ReadAll(reader)
+	if reader.hash.Sum64() != except {
+		t.Fatalf("crc64Reader rewind: except: %d, but got: %d\n", except, reader.hash.Sum64())
+	}
+}
+
+// crcTestRequest record the request, and response the CRC64 of body
+type crcTestRequest struct {
+	body          string
+	contentMD5    string
+	contentLength int64
+	// response a wrong CRC64
+	badCRC bool
+}
+
+func (got *crcTestRequest) handle(w http.ResponseWriter, r *http.Request) {
+	var data, _ = ioutil.ReadAll(r.Body)
+	got.body = string(data)
+	got.contentMD5 = r.Header.Get("Content-MD5")
+	got.contentLength = r.ContentLength
+	var crc = crc64.Checksum(data, crc64Table)
+	if got.badCRC {
+		crc++
+	}
+	w.Header().Set("x-oss-hash-crc64ecma", strconv.FormatUint(crc, 10))
+}
+
+func TestPutObjectCRC64(t *testing.T) {
+	var got = new(crcTestRequest)
+	var ts = httptest.NewServer(http.HandlerFunc(got.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	options.EnableCRC64 = true
+	var crcAPI, _ = NewAPI(options)
+
+	var err error
+	if err = crcAPI.PutObject("bucket", "object", strings.NewReader("hello world"), nil); err != nil {
+		t.Fatalf("PutObject: except: nil, but got: %s\n", err)
+	}
+	if got.body != "hello world" || got.contentMD5 != "" || got.contentLength != 11 {
+		t.Fatalf("PutObject: got unexcept request: %+v\n", got)
+	}
+
+	// the pipe is streamed with chunked encoding
+	var pr, pw = io.Pipe()
+	go func() {
+		pw.Write([]byte("hello "))
+		pw.Write([]byte("pipe"))
+		pw.Close()
+	}()
+	if err = crcAPI.PutObject("bucket", "object", pr, nil); err != nil {
+		t.Fatalf("PutObject pipe: except: nil, but got: %s\n", err)
+	}
+	if got.body != "hello pipe" || got.contentLength != -1 {
+		t.Fatalf("PutObject pipe: got unexcept request: %+v\n", got)
+	}
+
+	// the pipe with known length
+	pr, pw = io.Pipe()
+	go func() {
+		pw.Write([]byte("hello pipe"))
+		pw.Close()
+	}()
+	if _, err = crcAPI.AppendObject("bucket", "object", 0, pr, map[string]string{"Content-Length": "10"}); err != nil {
+		t.Fatalf("AppendObject: except: nil, but got: %s\n", err)
+	}
+	if got.body != "hello pipe" || got.contentLength != 10 {
+		t.Fatalf("AppendObject: got unexcept request: %+v\n", got)
+	}
+
+	var multi, _ = crcAPI.GetMultiPartUpload("bucket", "object", "uploadID")
+	if _, err = multi.UploadPart(1, strings.NewReader("part")); err != nil {
+		t.Fatalf("UploadPart: except: nil, but got: %s\n", err)
+	}
+}
+
+func TestPutObjectCRC64Mismatch(t *testing.T) {
+	var got = &crcTestRequest{badCRC: true}
+	var ts = httptest.NewServer(http.HandlerFunc(got.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	options.EnableCRC64 = true
+	var crcAPI, _ = NewAPI(options)
+
+	var err = crcAPI.PutObject("bucket", "object", strings.NewReader("hello world"), nil)
+	if !errors.Is(err, ErrCRC64Mismatch) {
+		t.Fatalf("PutObject: except: %s, but got: %v\n", ErrCRC64Mismatch, err)
+	}
+}
+
+func TestPutObjectMD5WithOffset(t *testing.T) {
+	var got = new(crcTestRequest)
+	var ts = httptest.NewServer(http.HandlerFunc(got.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var crcAPI, _ = NewAPI(options)
+
+	var reader = strings.NewReader("skip:hello")
+	reader.Seek(5, io.SeekStart)
+	if err := crcAPI.PutObject("bucket", "object", reader, nil); err != nil {
+		t.Fatalf("PutObject: except: nil, but got: %s\n", err)
+	}
+	var except = getBase64MD5([]byte("hello"))
+	if got.body != "hello" || got.contentMD5 != except || got.contentLength != 5 {
+		t.Fatalf("PutObject: got unexcept request: %+v\n", got)
+	}
+}
+
+func TestPutObjectReuseHeaders(t *testing.T) {
+	var got = new(crcTestRequest)
+	var ts = httptest.NewServer(http.HandlerFunc(got.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var crcAPI, _ = NewAPI(options)
+
+	var headers = map[string]string{"Content-Type": "text/plain"}
+	for _, body := range []string{"hello", "world"} {
+		if err := crcAPI.PutObject("bucket", "object", strings.NewReader(body), headers); err != nil {
+			t.Fatalf("PutObject: except: nil, but got: %s\n", err)
+		}
+		if got.contentMD5 != getBase64MD5([]byte(body)) {
+			t.Fatalf("PutObject: except: %s, but got: %s\n", getBase64MD5([]byte(body)), got.contentMD5)
+		}
+		crcAPI.AppendObject("bucket", "object", 0, strings.NewReader(body+"!"), headers)
+		if got.contentMD5 != getBase64MD5([]byte(body+"!")) {
+			t.Fatalf("AppendObject: except: %s, but got: %s\n", getBase64MD5([]byte(body+"!")), got.contentMD5)
+		}
+	}
+	if len(headers) != 1 {
+		t.Fatalf("PutObject: the headers of caller are changed: %v\n", headers)
+	}
+}
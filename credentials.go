@@ -0,0 +1,470 @@
+package oss
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials defined the access key to sign requests
+type Credentials struct {
+	// access key you create on aliyun console website
+	AccessID string
+	// access secret you create
+	SecretAccessKey string
+	// security token of temporary credentials, empty for a permanent access key
+	StsToken string
+	// the time the credentials expire, zero means never expire
+	Expiration time.Time
+}
+
+// Expired report whether the credentials expire within window
+func (creds *Credentials) Expired(window time.Duration) bool {
+	if creds.Expiration.IsZero() {
+		return false
+	}
+	return !time.Now().Add(window).Before(creds.Expiration)
+}
+
+// CredentialsProvider provide the credentials, it is consulted on every signing,
+// so a provider should cache the credentials itself.
+type CredentialsProvider interface {
+	GetCredentials(ctx context.Context) (*Credentials, error)
+}
+
+// ErrNoCredentials returned by a provider can not find any credentials
+var ErrNoCredentials = errors.New("oss: no credentials found")
+
+// StaticCredentialsProvider provide the fixed credentials
+type StaticCredentialsProvider struct {
+	Credentials Credentials
+}
+
+// NewStaticCredentialsProvider initial static credentials provider
+func NewStaticCredentialsProvider(accessID, secretAccessKey, stsToken string) *StaticCredentialsProvider {
+	return &StaticCredentialsProvider{
+		Credentials: Credentials{
+			AccessID:        accessID,
+			SecretAccessKey: secretAccessKey,
+			StsToken:        stsToken,
+		},
+	}
+}
+
+// GetCredentials get the fixed credentials
+func (provider *StaticCredentialsProvider) GetCredentials(ctx context.Context) (*Credentials, error) {
+	var creds = provider.Credentials
+	return &creds, nil
+}
+
+// Environment variables read by EnvCredentialsProvider
+const (
+	EnvAccessKeyID     = "OSS_ACCESS_KEY_ID"
+	EnvAccessKeySecret = "OSS_ACCESS_KEY_SECRET"
+	EnvSessionToken    = "OSS_SESSION_TOKEN"
+)
+
+// EnvCredentialsProvider provide the credentials from environment variables
+// OSS_ACCESS_KEY_ID, OSS_ACCESS_KEY_SECRET and OSS_SESSION_TOKEN
+type EnvCredentialsProvider struct{}
+
+// NewEnvCredentialsProvider initial environment variables credentials provider
+func NewEnvCredentialsProvider() *EnvCredentialsProvider {
+	return &EnvCredentialsProvider{}
+}
+
+// GetCredentials get the credentials from environment variables
+func (provider *EnvCredentialsProvider) GetCredentials(ctx context.Context) (*Credentials, error) {
+	var creds = &Credentials{
+		AccessID:        os.Getenv(EnvAccessKeyID),
+		SecretAccessKey: os.Getenv(EnvAccessKeySecret),
+		StsToken:        os.Getenv(EnvSessionToken),
+	}
+	if creds.AccessID == "" || creds.SecretAccessKey == "" {
+		return nil, ErrNoCredentials
+	}
+	return creds, nil
+}
+
+// FileCredentialsProvider provide the credentials from an ossutil style config file:
+//
+//	[Credentials]
+//	accessKeyID = your-access-key-id
+//	accessKeySecret = your-access-key-secret
+//	stsToken = optional-security-token
+//
+// The file is read again when it is modified.
+type FileCredentialsProvider struct {
+	// path of config file, default is ~/.ossutilconfig
+	Path string
+	// section of the credentials, default is Credentials
+	Section string
+
+	locker  sync.Mutex
+	modTime time.Time
+	creds   *Credentials
+}
+
+// NewFileCredentialsProvider initial config file credentials provider
+func NewFileCredentialsProvider(path string) *FileCredentialsProvider {
+	return &FileCredentialsProvider{Path: path}
+}
+
+func (provider *FileCredentialsProvider) path() string {
+	if provider.Path != "" {
+		return provider.Path
+	}
+	var home, err = os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ossutilconfig")
+}
+
+// GetCredentials get the credentials from config file
+func (provider *FileCredentialsProvider) GetCredentials(ctx context.Context) (*Credentials, error) {
+	provider.locker.Lock()
+	defer provider.locker.Unlock()
+
+	var path = provider.path()
+	var stat, err = os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoCredentials
+		}
+		return nil, err
+	}
+	if provider.creds == nil || !stat.ModTime().Equal(provider.modTime) {
+		var creds *Credentials
+		if creds, err = provider.load(path); err != nil {
+			return nil, err
+		}
+		provider.creds = creds
+		provider.modTime = stat.ModTime()
+	}
+	var creds = *provider.creds
+	return &creds, nil
+}
+
+func (provider *FileCredentialsProvider) load(path string) (*Credentials, error) {
+	var fp, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+
+	var section = provider.Section
+	if section == "" {
+		section = "Credentials"
+	}
+	var creds = new(Credentials)
+	var current string
+	var scanner = bufio.NewScanner(fp)
+	for scanner.Scan() {
+		var line = strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if current != section {
+			continue
+		}
+		var kv = strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		var value = strings.TrimSpace(kv[1])
+		switch strings.ToLower(strings.TrimSpace(kv[0])) {
+		case "accesskeyid":
+			creds.AccessID = value
+		case "accesskeysecret":
+			creds.SecretAccessKey = value
+		case "ststoken":
+			creds.StsToken = value
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if creds.AccessID == "" || creds.SecretAccessKey == "" {
+		return nil, ErrNoCredentials
+	}
+	return creds, nil
+}
+
+// RefreshingCredentialsProvider cache the temporary credentials got by Fetch,
+// and fetch new one before they expire.
+type RefreshingCredentialsProvider struct {
+	// Fetch get new temporary credentials, e.g. from STS AssumeRole
+	Fetch func(ctx context.Context) (*Credentials, error)
+	// refresh the credentials when they expire within Window, default is 5 minutes
+	Window time.Duration
+
+	locker  sync.Mutex
+	creds   *Credentials
+	refresh *credentialsRefresh
+}
+
+// credentialsRefresh NOT public API
+// The Fetch in flight, the creds and err are set before done is closed.
+type credentialsRefresh struct {
+	done  chan struct{}
+	creds *Credentials
+	err   error
+}
+
+// NewRefreshingCredentialsProvider initial refreshing credentials provider
+func NewRefreshingCredentialsProvider(fetch func(ctx context.Context) (*Credentials, error)) *RefreshingCredentialsProvider {
+	return &RefreshingCredentialsProvider{
+		Fetch:  fetch,
+		Window: 5 * time.Minute,
+	}
+}
+
+// GetCredentials get the cached credentials or fetch new one.
+// Only one Fetch runs at a time, the other callers use the cached credentials while they
+// do not really expire, or wait for the Fetch.
+// If fetch failed, the cached credentials are used until they really expire.
+func (provider *RefreshingCredentialsProvider) GetCredentials(ctx context.Context) (*Credentials, error) {
+	provider.locker.Lock()
+	var cached = provider.creds
+	if cached != nil && !cached.Expired(provider.Window) {
+		provider.locker.Unlock()
+		var creds = *cached
+		return &creds, nil
+	}
+	var refresh = provider.refresh
+	var fetching = refresh == nil
+	if fetching {
+		refresh = &credentialsRefresh{done: make(chan struct{})}
+		provider.refresh = refresh
+	}
+	provider.locker.Unlock()
+
+	if fetching {
+		// the Fetch runs without the lock, so the other callers are not blocked by it
+		refresh.creds, refresh.err = provider.Fetch(ctx)
+		provider.locker.Lock()
+		if refresh.err == nil {
+			provider.creds = refresh.creds
+		}
+		provider.refresh = nil
+		provider.locker.Unlock()
+		close(refresh.done)
+	} else if cached != nil && !cached.Expired(0) {
+		var creds = *cached
+		return &creds, nil
+	} else {
+		select {
+		case <-refresh.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if refresh.err != nil {
+		if cached != nil && !cached.Expired(0) {
+			var creds = *cached
+			return &creds, nil
+		}
+		return nil, refresh.err
+	}
+	var creds = *refresh.creds
+	return &creds, nil
+}
+
+// ECSMetadataURL the metadata service of ECS to get the RAM role credentials
+var ECSMetadataURL = "http://100.100.100.200/latest/meta-data/ram/security-credentials/"
+
+// NewECSRoleCredentialsProvider initial refreshing credentials provider,
+// the STS credentials come from the RAM role attached to the ECS instance.
+func NewECSRoleCredentialsProvider(roleName string) *RefreshingCredentialsProvider {
+	var client = &http.Client{Timeout: 5 * time.Second}
+	return NewRefreshingCredentialsProvider(func(ctx context.Context) (*Credentials, error) {
+		var req, err = http.NewRequestWithContext(ctx, "GET", ECSMetadataURL+roleName, nil)
+		if err != nil {
+			return nil, err
+		}
+		var res *http.Response
+		if res, err = client.Do(req); err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+		var data []byte
+		if data, err = ioutil.ReadAll(res.Body); err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("oss: get ECS role credentials failed: %s %s", res.Status, data)
+		}
+		var result struct {
+			Code            string
+			AccessKeyID     string `json:"AccessKeyId"`
+			AccessKeySecret string
+			SecurityToken   string
+			Expiration      time.Time
+		}
+		if err = json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+		if result.Code != "" && result.Code != "Success" {
+			return nil, fmt.Errorf("oss: get ECS role credentials failed: %s", result.Code)
+		}
+		return &Credentials{
+			AccessID:        result.AccessKeyID,
+			SecretAccessKey: result.AccessKeySecret,
+			StsToken:        result.SecurityToken,
+			Expiration:      result.Expiration,
+		}, nil
+	})
+}
+
+// STSEndpoint the endpoint of STS to assume the RAM role
+var STSEndpoint = "https://sts.aliyuncs.com/"
+
+// NewSTSAssumeRoleCredentialsProvider initial refreshing credentials provider,
+// the STS credentials come from AssumeRole of the roleArn with the access key of a RAM user.
+// The duration of credentials is in [15 minutes, the max session duration of role], 0 means 1 hour.
+func NewSTSAssumeRoleCredentialsProvider(accessID, secretAccessKey, roleArn, sessionName string,
+	duration time.Duration) *RefreshingCredentialsProvider {
+
+	if duration <= 0 {
+		duration = time.Hour
+	}
+	var client = &http.Client{Timeout: 10 * time.Second}
+	return NewRefreshingCredentialsProvider(func(ctx context.Context) (*Credentials, error) {
+		var nonce = make([]byte, 16)
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		var params = map[string]string{
+			"Action":           "AssumeRole",
+			"RoleArn":          roleArn,
+			"RoleSessionName":  sessionName,
+			"DurationSeconds":  strconv.FormatInt(int64(duration/time.Second), 10),
+			"Format":           "JSON",
+			"Version":          "2015-04-01",
+			"AccessKeyId":      accessID,
+			"SignatureMethod":  "HMAC-SHA1",
+			"SignatureVersion": "1.0",
+			"SignatureNonce":   hex.EncodeToString(nonce),
+			"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		}
+		var query = getCanonicalizedRPCQuery(params)
+		var h = hmac.New(sha1.New, []byte(secretAccessKey+"&"))
+		h.Write([]byte("GET&" + rpcEscape("/") + "&" + rpcEscape(query)))
+		query += "&Signature=" + rpcEscape(base64.StdEncoding.EncodeToString(h.Sum(nil)))
+
+		var req, err = http.NewRequestWithContext(ctx, "GET", STSEndpoint+"?"+query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var res *http.Response
+		if res, err = client.Do(req); err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+		var data []byte
+		if data, err = ioutil.ReadAll(res.Body); err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("oss: assume role failed: %s %s", res.Status, data)
+		}
+		var result struct {
+			Credentials struct {
+				AccessKeyID     string `json:"AccessKeyId"`
+				AccessKeySecret string
+				SecurityToken   string
+				Expiration      time.Time
+			}
+		}
+		if err = json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+		return &Credentials{
+			AccessID:        result.Credentials.AccessKeyID,
+			SecretAccessKey: result.Credentials.AccessKeySecret,
+			StsToken:        result.Credentials.SecurityToken,
+			Expiration:      result.Credentials.Expiration,
+		}, nil
+	})
+}
+
+// getCanonicalizedRPCQuery NOT public API
+// The sorted and escaped query of the RPC style API, e.g.: STS.
+func getCanonicalizedRPCQuery(params map[string]string) string {
+	var keys = make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs = make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, rpcEscape(k)+"="+rpcEscape(params[k]))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// rpcEscape NOT public API
+// Escape the str by RFC 3986 for the signature of RPC style API.
+func rpcEscape(str string) string {
+	var escaped = url.QueryEscape(str)
+	escaped = strings.Replace(escaped, "+", "%20", -1)
+	escaped = strings.Replace(escaped, "*", "%2A", -1)
+	return strings.Replace(escaped, "%7E", "~", -1)
+}
+
+// ChainCredentialsProvider try the providers in order,
+// and use the first one which get the credentials successful.
+type ChainCredentialsProvider struct {
+	Providers []CredentialsProvider
+}
+
+// NewChainCredentialsProvider initial chain credentials provider
+func NewChainCredentialsProvider(providers ...CredentialsProvider) *ChainCredentialsProvider {
+	return &ChainCredentialsProvider{Providers: providers}
+}
+
+// NewDefaultCredentialsChain initial the chain of environment variables and ~/.ossutilconfig
+func NewDefaultCredentialsChain() *ChainCredentialsProvider {
+	return NewChainCredentialsProvider(NewEnvCredentialsProvider(), NewFileCredentialsProvider(""))
+}
+
+// GetCredentials get the credentials from the first available provider
+func (provider *ChainCredentialsProvider) GetCredentials(ctx context.Context) (*Credentials, error) {
+	var errs []string
+	for _, p := range provider.Providers {
+		var creds, err = p.GetCredentials(ctx)
+		if err == nil {
+			return creds, nil
+		}
+		if err != ErrNoCredentials {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("oss: no credentials found: %s", strings.Join(errs, "; "))
+	}
+	return nil, ErrNoCredentials
+}
@@ -0,0 +1,237 @@
+package oss
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnvCredentialsProvider(t *testing.T) {
+	t.Setenv(EnvAccessKeyID, "")
+	t.Setenv(EnvAccessKeySecret, "")
+	var provider = NewEnvCredentialsProvider()
+	if _, err := provider.GetCredentials(context.Background()); err != ErrNoCredentials {
+		t.Fatalf("GetCredentials: except: %v, but got: %v\n", ErrNoCredentials, err)
+	}
+	t.Setenv(EnvAccessKeyID, "id")
+	t.Setenv(EnvAccessKeySecret, "secret")
+	t.Setenv(EnvSessionToken, "token")
+	var creds, err = provider.GetCredentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessID != "id" || creds.SecretAccessKey != "secret" || creds.StsToken != "token" {
+		t.Fatalf("GetCredentials: got unexcept credentials: %v\n", creds)
+	}
+}
+
+func TestFileCredentialsProvider(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "ossutilconfig")
+	var provider = NewFileCredentialsProvider(path)
+	if _, err := provider.GetCredentials(context.Background()); err != ErrNoCredentials {
+		t.Fatalf("GetCredentials: except: %v, but got: %v\n", ErrNoCredentials, err)
+	}
+	var config = `
+[Other]
+accessKeyID = other
+[Credentials]
+language = EN
+accessKeyID = id
+accessKeySecret = secret
+`
+	if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var creds, err = provider.GetCredentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessID != "id" || creds.SecretAccessKey != "secret" || creds.StsToken != "" {
+		t.Fatalf("GetCredentials: got unexcept credentials: %v\n", creds)
+	}
+
+	if err = ioutil.WriteFile(path, []byte(config+"stsToken = token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var later = time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	if creds, err = provider.GetCredentials(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if creds.StsToken != "token" {
+		t.Fatalf("GetCredentials: except: %s, but got: %s\n", "token", creds.StsToken)
+	}
+}
+
+func TestRefreshingCredentialsProvider(t *testing.T) {
+	var fetched = 0
+	var fail = false
+	var provider = NewRefreshingCredentialsProvider(func(ctx context.Context) (*Credentials, error) {
+		if fail {
+			return nil, errors.New("fetch failed")
+		}
+		fetched++
+		return &Credentials{
+			AccessID:        "id",
+			SecretAccessKey: "secret",
+			StsToken:        "token",
+			Expiration:      time.Now().Add(time.Hour),
+		}, nil
+	})
+	for i := 0; i < 3; i++ {
+		if _, err := provider.GetCredentials(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetched != 1 {
+		t.Fatalf("fetched: except: %d, but got: %d\n", 1, fetched)
+	}
+
+	provider.Window = 2 * time.Hour
+	if _, err := provider.GetCredentials(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if fetched != 2 {
+		t.Fatalf("fetched: except: %d, but got: %d\n", 2, fetched)
+	}
+
+	fail = true
+	if _, err := provider.GetCredentials(context.Background()); err != nil {
+		t.Fatalf("GetCredentials: except the cached credentials, but got: %v\n", err)
+	}
+}
+
+func TestRefreshingCredentialsProviderConcurrent(t *testing.T) {
+	var fetched int32
+	var release = make(chan struct{})
+	var provider = NewRefreshingCredentialsProvider(func(ctx context.Context) (*Credentials, error) {
+		atomic.AddInt32(&fetched, 1)
+		<-release
+		return &Credentials{AccessID: "new", SecretAccessKey: "secret", Expiration: time.Now().Add(time.Hour)}, nil
+	})
+	// the cached credentials expire within Window, but they are still valid
+	provider.creds = &Credentials{AccessID: "old", SecretAccessKey: "secret", Expiration: time.Now().Add(time.Minute)}
+
+	var done = make(chan *Credentials)
+	go func() {
+		var creds, _ = provider.GetCredentials(context.Background())
+		done <- creds
+	}()
+	for atomic.LoadInt32(&fetched) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// the other callers are not blocked by the Fetch
+	for i := 0; i < 3; i++ {
+		var creds, err = provider.GetCredentials(context.Background())
+		if err != nil || creds.AccessID != "old" {
+			t.Fatalf("GetCredentials: except: the cached credentials, but got: %v %v\n", creds, err)
+		}
+	}
+	close(release)
+	if creds := <-done; creds == nil || creds.AccessID != "new" {
+		t.Fatalf("GetCredentials: except: the fetched credentials, but got: %v\n", creds)
+	}
+	if creds, _ := provider.GetCredentials(context.Background()); creds.AccessID != "new" {
+		t.Fatalf("GetCredentials: except: %s, but got: %s\n", "new", creds.AccessID)
+	}
+	if fetched != 1 {
+		t.Fatalf("fetched: except: %d, but got: %d\n", 1, fetched)
+	}
+}
+
+func TestSTSAssumeRoleCredentialsProvider(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var query = req.URL.Query()
+		var signature = query.Get("Signature")
+		query.Del("Signature")
+		var h = hmac.New(sha1.New, []byte("secret&"))
+		h.Write([]byte("GET&%2F&" + url.QueryEscape(query.Encode())))
+		if signature != base64.StdEncoding.EncodeToString(h.Sum(nil)) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"Code":"SignatureDoesNotMatch"}`))
+			return
+		}
+		if query.Get("Action") != "AssumeRole" || query.Get("RoleArn") != "acs:ram::1:role/oss" ||
+			query.Get("RoleSessionName") != "session" || query.Get("DurationSeconds") != "900" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"Code":"InvalidParameter"}`))
+			return
+		}
+		w.Write([]byte(`{"Credentials":{"AccessKeyId":"STS.id","AccessKeySecret":"sts-secret",` +
+			`"SecurityToken":"token","Expiration":"2030-01-01T00:00:00Z"}}`))
+	}))
+	defer ts.Close()
+	var endpoint = STSEndpoint
+	STSEndpoint = ts.URL + "/"
+	defer func() { STSEndpoint = endpoint }()
+
+	var provider = NewSTSAssumeRoleCredentialsProvider("id", "secret", "acs:ram::1:role/oss", "session", 15*time.Minute)
+	var creds, err = provider.GetCredentials(context.Background())
+	if err != nil {
+		t.Fatalf("GetCredentials: except: nil, but got: %s\n", err)
+	}
+	if creds.AccessID != "STS.id" || creds.SecretAccessKey != "sts-secret" || creds.StsToken != "token" ||
+		creds.Expiration.Year() != 2030 {
+		t.Fatalf("GetCredentials: got unexcept credentials: %+v\n", creds)
+	}
+}
+
+func TestChainCredentialsProvider(t *testing.T) {
+	t.Setenv(EnvAccessKeyID, "")
+	var chain = NewChainCredentialsProvider(NewEnvCredentialsProvider(),
+		NewStaticCredentialsProvider("id", "secret", ""))
+	var creds, err = chain.GetCredentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessID != "id" {
+		t.Fatalf("GetCredentials: except: %s, but got: %s\n", "id", creds.AccessID)
+	}
+
+	chain = NewChainCredentialsProvider(NewEnvCredentialsProvider())
+	if _, err = chain.GetCredentials(context.Background()); err != ErrNoCredentials {
+		t.Fatalf("GetCredentials: except: %v, but got: %v\n", ErrNoCredentials, err)
+	}
+}
+
+func TestCredentialsProviderRequest(t *testing.T) {
+	var tokens []string
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tokens = append(tokens, req.Header.Get("x-oss-security-token"))
+	}))
+	defer ts.Close()
+
+	var token = "token1"
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	options.CredentialsProvider = NewRefreshingCredentialsProvider(func(ctx context.Context) (*Credentials, error) {
+		return &Credentials{
+			AccessID:        "id",
+			SecretAccessKey: "secret",
+			StsToken:        token,
+			Expiration:      time.Now(),
+		}, nil
+	})
+	var api, _ = NewAPI(options)
+	if err := api.DeleteObject("bucket", "object"); err != nil {
+		t.Fatal(err)
+	}
+	token = "token2"
+	if err := api.DeleteObject("bucket", "object"); err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 || tokens[0] != "token1" || tokens[1] != "token2" {
+		t.Fatalf("security token: except: %v, but got: %v\n", []string{"token1", "token2"}, tokens)
+	}
+}
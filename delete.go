@@ -0,0 +1,124 @@
+package oss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MaxDeleteObjects defined the max keys of one DeleteObjects request
+const MaxDeleteObjects = 1000
+
+// DeleteOptions defined the options of batch delete
+type DeleteOptions struct {
+	// the number of DeleteObjects requests at the same time, default is 3
+	Concurrency int
+}
+
+// GetDefaultDeleteOptions get default delete options
+func GetDefaultDeleteOptions() *DeleteOptions {
+	return &DeleteOptions{
+		Concurrency: 3,
+	}
+}
+
+// BatchDeleteObjects delete any number of objects, the keys are split into requests of MaxDeleteObjects keys
+// and they are sent concurrently, nil opts means GetDefaultDeleteOptions().
+// The deleted keys and the keys failed to delete are collected into the result.
+// If any key failed to delete, the result is returned with an error of the first failure.
+func (api *API) BatchDeleteObjects(bucket string, objects []string, opts *DeleteOptions) (*DeleteResult, error) {
+	return api.BatchDeleteObjectsWithContext(context.Background(), bucket, objects, opts)
+}
+
+// BatchDeleteObjectsWithContext is like BatchDeleteObjects, but with a context to cancel the requests.
+func (api *API) BatchDeleteObjectsWithContext(ctx context.Context, bucket string, objects []string,
+	opts *DeleteOptions) (*DeleteResult, error) {
+
+	var result = new(DeleteResult)
+	if err := api.batchDelete(ctx, bucket, objects, opts, result); err != nil {
+		return result, err
+	}
+	return result, getDeleteError(result)
+}
+
+// DeletePrefix delete all the objects whose key begins with prefix, nil opts means GetDefaultDeleteOptions().
+// The objects are listed and deleted by BatchDeleteObjects round by round, the prefix must not be empty.
+func (api *API) DeletePrefix(bucket, prefix string, opts *DeleteOptions) (*DeleteResult, error) {
+	return api.DeletePrefixWithContext(context.Background(), bucket, prefix, opts)
+}
+
+// DeletePrefixWithContext is like DeletePrefix, but with a context to cancel the requests.
+func (api *API) DeletePrefixWithContext(ctx context.Context, bucket, prefix string,
+	opts *DeleteOptions) (*DeleteResult, error) {
+
+	if prefix == "" {
+		return nil, errors.New("oss: delete prefix is empty")
+	}
+	if opts == nil {
+		opts = GetDefaultDeleteOptions()
+	}
+	var roundSize = MaxDeleteObjects
+	if opts.Concurrency > 1 {
+		roundSize *= opts.Concurrency
+	}
+
+	var result = new(DeleteResult)
+	var iter = api.ListObjectsWithContext(ctx, bucket, &ListObjectsOptions{Prefix: prefix, MaxKeys: MaxDeleteObjects})
+	var keys []string
+	for {
+		var more = iter.Next()
+		if more {
+			keys = append(keys, iter.Object().Key)
+		}
+		if len(keys) == roundSize || (!more && len(keys) > 0) {
+			// the deleted keys are before the marker of listing, they never appear again
+			if err := api.batchDelete(ctx, bucket, keys, opts, result); err != nil {
+				return result, err
+			}
+			keys = nil
+		}
+		if !more {
+			break
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return result, err
+	}
+	return result, getDeleteError(result)
+}
+
+// batchDelete NOT public API
+// Delete the objects by requests of MaxDeleteObjects keys, the results are merged into result.
+func (api *API) batchDelete(ctx context.Context, bucket string, objects []string, opts *DeleteOptions,
+	result *DeleteResult) error {
+
+	if opts == nil {
+		opts = GetDefaultDeleteOptions()
+	}
+	var locker sync.Mutex
+	var count = (len(objects) + MaxDeleteObjects - 1) / MaxDeleteObjects
+	return runParallel(ctx, count, opts.Concurrency, nil, func(ctx context.Context, n int) error {
+		var end = n * MaxDeleteObjects
+		if end > len(objects) {
+			end = len(objects)
+		}
+		var chunkResult DeleteResult
+		if err := api.DeleteObjectsWithContext(ctx, bucket, objects[(n-1)*MaxDeleteObjects:end], &chunkResult); err != nil {
+			return err
+		}
+		locker.Lock()
+		result.Objects = append(result.Objects, chunkResult.Objects...)
+		result.Errors = append(result.Errors, chunkResult.Errors...)
+		locker.Unlock()
+		return nil
+	})
+}
+
+// getDeleteError get the error of the first key failed to delete
+func getDeleteError(result *DeleteResult) error {
+	if len(result.Errors) == 0 {
+		return nil
+	}
+	return fmt.Errorf("oss: %d objects failed to delete, the first: %w", len(result.Errors), result.Errors[0])
+}
@@ -0,0 +1,143 @@
+package oss
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// deleteTestServer serve ListBucket and DeleteObjects of the sorted keys
+type deleteTestServer struct {
+	locker   sync.Mutex
+	keys     []string
+	requests int
+	// the keys failed to delete with AccessDenied
+	denied map[string]bool
+}
+
+func (s *deleteTestServer) handle(w http.ResponseWriter, req *http.Request) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	var query = req.URL.Query()
+	if req.Method == "GET" {
+		var contents []string
+		var truncated = false
+		for _, key := range s.keys {
+			if key <= query.Get("marker") || !strings.HasPrefix(key, query.Get("prefix")) {
+				continue
+			}
+			if len(contents) == MaxDeleteObjects {
+				truncated = true
+				break
+			}
+			contents = append(contents, fmt.Sprintf("<Contents><Key>%s</Key></Contents>", key))
+		}
+		fmt.Fprintf(w, "<ListBucketResult><IsTruncated>%v</IsTruncated>%s</ListBucketResult>",
+			truncated, strings.Join(contents, ""))
+		return
+	}
+
+	s.requests++
+	var body, _ = ioutil.ReadAll(req.Body)
+	var deleteXML struct {
+		Objects []ObjectKey `xml:"Object"`
+	}
+	xml.Unmarshal(body, &deleteXML)
+	if len(deleteXML.Objects) > MaxDeleteObjects {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "<Error><Code>MalformedXML</Code></Error>")
+		return
+	}
+	var deleted = make(map[string]bool)
+	var result = "<DeleteResult>"
+	for _, object := range deleteXML.Objects {
+		if s.denied[object.Key] {
+			result += fmt.Sprintf("<Error><Key>%s</Key><Code>AccessDenied</Code><Message>denied</Message></Error>", object.Key)
+			continue
+		}
+		deleted[object.Key] = true
+		result += fmt.Sprintf("<Deleted><Key>%s</Key></Deleted>", object.Key)
+	}
+	var keys []string
+	for _, key := range s.keys {
+		if !deleted[key] {
+			keys = append(keys, key)
+		}
+	}
+	s.keys = keys
+	fmt.Fprint(w, result+"</DeleteResult>")
+}
+
+// getDeleteTestKeys get count sorted keys with prefix
+func getDeleteTestKeys(count int, prefix string) []string {
+	var keys []string
+	for i := 0; i < count; i++ {
+		keys = append(keys, fmt.Sprintf("%s%05d", prefix, i))
+	}
+	return keys
+}
+
+func TestBatchDeleteObjects(t *testing.T) {
+	var server = &deleteTestServer{keys: getDeleteTestKeys(2500, "tmp/"), denied: make(map[string]bool)}
+	var deleteAPI, closeServer = newRetryTestAPI(t, server.handle)
+	defer closeServer()
+
+	var result, err = deleteAPI.BatchDeleteObjects("bucket", server.keys, nil)
+	if err != nil {
+		t.Fatalf("BatchDeleteObjects: except: nil, but got: %s\n", err)
+	}
+	if len(result.Objects) != 2500 || server.requests != 3 || len(server.keys) != 0 {
+		t.Fatalf("BatchDeleteObjects: except: %d deleted by %d requests, but got: %d %d\n",
+			2500, 3, len(result.Objects), server.requests)
+	}
+}
+
+func TestBatchDeleteObjectsFailed(t *testing.T) {
+	var server = &deleteTestServer{keys: getDeleteTestKeys(1500, "tmp/"), denied: make(map[string]bool)}
+	var deleteAPI, closeServer = newRetryTestAPI(t, server.handle)
+	defer closeServer()
+	server.denied["tmp/01200"] = true
+
+	var result, err = deleteAPI.BatchDeleteObjects("bucket", append([]string(nil), server.keys...), nil)
+	if err == nil {
+		t.Fatalf("BatchDeleteObjects: except: AccessDenied, but got: nil\n")
+	}
+	if len(result.Objects) != 1499 || len(result.Errors) != 1 {
+		t.Fatalf("BatchDeleteObjects: except: %d deleted and %d failed, but got: %d %d\n",
+			1499, 1, len(result.Objects), len(result.Errors))
+	}
+	if result.Errors[0].Key != "tmp/01200" || result.Errors[0].Code != "AccessDenied" {
+		t.Fatalf("BatchDeleteObjects: got unexcept error: %+v\n", result.Errors[0])
+	}
+}
+
+func TestDeletePrefix(t *testing.T) {
+	var server = &deleteTestServer{keys: getDeleteTestKeys(4500, "tenant/"), denied: make(map[string]bool)}
+	var deleteAPI, closeServer = newRetryTestAPI(t, server.handle)
+	defer closeServer()
+	server.keys = append(server.keys, "other/1", "tenant")
+	sort.Strings(server.keys)
+
+	var opts = GetDefaultDeleteOptions()
+	opts.Concurrency = 2
+	var result, err = deleteAPI.DeletePrefix("bucket", "tenant/", opts)
+	if err != nil {
+		t.Fatalf("DeletePrefix: except: nil, but got: %s\n", err)
+	}
+	if len(result.Objects) != 4500 || server.requests != 5 {
+		t.Fatalf("DeletePrefix: except: %d deleted by %d requests, but got: %d %d\n",
+			4500, 5, len(result.Objects), server.requests)
+	}
+	if strings.Join(server.keys, ",") != "other/1,tenant" {
+		t.Fatalf("DeletePrefix: except: %s, but got: %s\n", "other/1,tenant", strings.Join(server.keys, ","))
+	}
+
+	if _, err = deleteAPI.DeletePrefix("bucket", "", nil); err == nil {
+		t.Fatalf("DeletePrefix: except: error of empty prefix, but got: nil\n")
+	}
+}
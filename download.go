@@ -0,0 +1,243 @@
+package oss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DownloadOptions defined the options of parallel ranged download
+type DownloadOptions struct {
+	// the size of each range, default is 8MB
+	PartSize int64
+	// the number of ranges downloading at the same time, default is 3
+	Concurrency int
+	// the checkpoint file to resume the download.
+	// The progress is saved into it, when the download failed, download again with the same checkpoint
+	// only downloads the missing ranges. It is removed after the download completed.
+	Checkpoint string
+	// listen the progress of download, the listener of ctx is used if it is nil
+	Progress ProgressListener
+}
+
+// GetDefaultDownloadOptions get default download options
+func GetDefaultDownloadOptions() *DownloadOptions {
+	return &DownloadOptions{
+		PartSize:    8 * 1024 * 1024,
+		Concurrency: 3,
+	}
+}
+
+// ErrRangeNotSupported returned when the server ignore the Range header
+var ErrRangeNotSupported = errors.New("oss: range request is not supported")
+
+// downloadCheckpoint defined the progress of resumable download saved in the checkpoint file
+type downloadCheckpoint struct {
+	Bucket   string
+	Object   string
+	FilePath string
+	Size     int64
+	ETag     string
+	PartSize int64
+	// the downloaded ranges
+	Parts map[int]bool
+
+	locker sync.Mutex
+	path   string
+}
+
+// isValid check the checkpoint is for the same object and the temporary file is kept
+func (cp *downloadCheckpoint) isValid(bucket, object, filePath string, meta *ObjectMeta, partSize int64) bool {
+	if cp.Bucket != bucket || cp.Object != object || cp.FilePath != filePath ||
+		cp.Size != meta.Size || cp.ETag != meta.ETag || cp.PartSize != partSize {
+		return false
+	}
+	var stat, err = os.Stat(filePath + ".tmp")
+	return err == nil && stat.Size() == meta.Size
+}
+
+// partLength get the length of range
+func (cp *downloadCheckpoint) partLength(partNumber int) int64 {
+	var start = int64(partNumber-1) * cp.PartSize
+	if start+cp.PartSize > cp.Size {
+		return cp.Size - start
+	}
+	return cp.PartSize
+}
+
+// addPart record the downloaded range and save the checkpoint
+func (cp *downloadCheckpoint) addPart(partNumber int) error {
+	cp.locker.Lock()
+	defer cp.locker.Unlock()
+	cp.Parts[partNumber] = true
+	if cp.path == "" {
+		return nil
+	}
+	return saveCheckpoint(cp.path, cp)
+}
+
+// DownloadFile download the object into fileName with parallel ranged GET, nil opts means GetDefaultDownloadOptions().
+// The data is written into fileName.tmp, and it is renamed to fileName after the ETag and CRC64 are checked.
+func (api *API) DownloadFile(bucket, object, fileName string, opts *DownloadOptions) error {
+	return api.DownloadFileWithContext(context.Background(), bucket, object, fileName, opts)
+}
+
+// DownloadFileWithContext is like DownloadFile, but with a context to cancel the download.
+func (api *API) DownloadFileWithContext(ctx context.Context, bucket, object, fileName string,
+	opts *DownloadOptions) (err error) {
+
+	if opts == nil {
+		opts = GetDefaultDownloadOptions()
+	}
+	var filePath string
+	if filePath, err = filepath.Abs(fileName); err != nil {
+		return err
+	}
+
+	var meta *ObjectMeta
+	if meta, err = api.HeadObjectWithContext(ctx, bucket, object, nil); err != nil {
+		return err
+	}
+	var partSize = opts.PartSize
+	if partSize <= 0 {
+		partSize = GetDefaultDownloadOptions().PartSize
+	}
+
+	var cp = new(downloadCheckpoint)
+	if opts.Checkpoint == "" || loadCheckpoint(opts.Checkpoint, cp) != nil ||
+		!cp.isValid(bucket, object, filePath, meta, partSize) {
+		cp = &downloadCheckpoint{
+			Bucket:   bucket,
+			Object:   object,
+			FilePath: filePath,
+			Size:     meta.Size,
+			ETag:     meta.ETag,
+			PartSize: partSize,
+			Parts:    make(map[int]bool),
+		}
+		os.Remove(filePath + ".tmp")
+	}
+	if cp.Parts == nil {
+		cp.Parts = make(map[int]bool)
+	}
+	cp.path = opts.Checkpoint
+
+	var progress = newProgressTracker(getProgressListener(ctx, opts.Progress), meta.Size)
+	for partNumber := range cp.Parts {
+		progress.skip(cp.partLength(partNumber))
+	}
+	progress.started()
+	defer func() {
+		progress.finish(err)
+	}()
+
+	var tmpPath = filePath + ".tmp"
+	var fp *os.File
+	if fp, err = os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE, 0644); err != nil {
+		return err
+	}
+	if err = api.downloadParts(ctx, bucket, object, fp, cp, opts.Concurrency, progress); err == nil {
+		err = checkFileCRC64(fp, meta)
+	}
+	fp.Close()
+	if err != nil {
+		if opts.Checkpoint == "" {
+			os.Remove(tmpPath)
+		}
+		return err
+	}
+	if err = os.Rename(tmpPath, filePath); err != nil {
+		return err
+	}
+	if opts.Checkpoint != "" {
+		os.Remove(opts.Checkpoint)
+	}
+	return nil
+}
+
+// downloadParts NOT public API
+// Download the ranges not in the checkpoint into fp, the progress of ranges is added to progress.
+func (api *API) downloadParts(ctx context.Context, bucket, object string, fp *os.File,
+	cp *downloadCheckpoint, concurrency int, progress *progressTracker) error {
+
+	if err := fp.Truncate(cp.Size); err != nil {
+		return err
+	}
+	if cp.path != "" {
+		if err := saveCheckpoint(cp.path, cp); err != nil {
+			return err
+		}
+	}
+
+	var partCount = int((cp.Size + cp.PartSize - 1) / cp.PartSize)
+	var skip = func(partNumber int) bool {
+		cp.locker.Lock()
+		defer cp.locker.Unlock()
+		return cp.Parts[partNumber]
+	}
+	return runParallel(ctx, partCount, concurrency, skip, func(ctx context.Context, partNumber int) error {
+		var start = int64(partNumber-1) * cp.PartSize
+		var length = cp.partLength(partNumber)
+		var body, err = api.getObjectRange(progress.partContext(ctx), bucket, object, start, length, cp.ETag)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		var n int64
+		if n, err = io.CopyN(io.NewOffsetWriter(fp, start), body, length); err != nil {
+			return err
+		}
+		if n != length {
+			return io.ErrUnexpectedEOF
+		}
+		// the range is downloaded again next time if the checkpoint is not saved
+		cp.addPart(partNumber)
+		progress.partCompleted(partNumber)
+		return nil
+	})
+}
+
+// getObjectRange NOT public API
+// Get the range [start, start+length) of object, the request failed if the ETag is changed.
+func (api *API) getObjectRange(ctx context.Context, bucket, object string, start, length int64,
+	etag string) (io.ReadCloser, error) {
+
+	var options = getDefaultRequestOptions()
+	options.Context = ctx
+	options.Bucket = bucket
+	options.Object = object
+	options.Headers["Range"] = fmt.Sprintf("bytes=%d-%d", start, start+length-1)
+	if etag != "" {
+		options.Headers["If-Match"] = etag
+	}
+	options.Progress = getProgressListener(ctx, nil)
+
+	var res, err = api.httpRequest(options)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusPartialContent {
+		// the body is the whole object, close without reading it
+		res.Body.Close()
+		return nil, ErrRangeNotSupported
+	}
+	return res.Body, nil
+}
+
+// checkFileCRC64 compare the CRC64 of file with the one of object meta
+func checkFileCRC64(fp *os.File, meta *ObjectMeta) error {
+	if meta.Header.Get("x-oss-hash-crc64ecma") == "" {
+		return nil
+	}
+	var crc = crc64.New(crc64Table)
+	if _, err := io.Copy(crc, io.NewSectionReader(fp, 0, meta.Size)); err != nil {
+		return err
+	}
+	return checkCRC64(crc, meta.Header)
+}
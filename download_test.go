@@ -0,0 +1,133 @@
+package oss
+
+import (
+	"bytes"
+	"errors"
+	"hash/crc64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDownloadFile(t *testing.T) {
+	var data = bytes.Repeat([]byte("0123456789"), 1000)
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("x-oss-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(data, crc64Table), 10))
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var downloadAPI, _ = NewAPI(options)
+
+	var dir, err = ioutil.TempDir("", "oss-go-sdk-download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var fileName = filepath.Join(dir, "file")
+
+	var opts = GetDefaultDownloadOptions()
+	opts.PartSize = 1024
+	opts.Concurrency = 4
+	if err = downloadAPI.DownloadFile("bucket", "object", fileName, opts); err != nil {
+		t.Fatalf("DownloadFile: except: nil, but got: %s\n", err)
+	}
+	var got, _ = ioutil.ReadFile(fileName)
+	if !bytes.Equal(got, data) {
+		t.Fatalf("DownloadFile: the file is not equal to the object\n")
+	}
+	if _, err = os.Stat(fileName + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("DownloadFile: the temporary file is not removed\n")
+	}
+}
+
+func TestDownloadFileResume(t *testing.T) {
+	var data = bytes.Repeat([]byte("0123456789"), 1000)
+	// the ranges start with failRange failed
+	var failRange = "bytes=5120-"
+	var count int
+	var locker sync.Mutex
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("x-oss-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(data, crc64Table), 10))
+		if r.Method == "GET" {
+			locker.Lock()
+			count++
+			locker.Unlock()
+			if failRange != "" && strings.HasPrefix(r.Header.Get("Range"), failRange) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var downloadAPI, _ = NewAPI(options)
+
+	var dir, err = ioutil.TempDir("", "oss-go-sdk-download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var fileName = filepath.Join(dir, "file")
+
+	var opts = GetDefaultDownloadOptions()
+	opts.PartSize = 1024
+	opts.Concurrency = 1
+	opts.Checkpoint = filepath.Join(dir, "file.cp")
+	if err = downloadAPI.DownloadFile("bucket", "object", fileName, opts); err == nil {
+		t.Fatalf("DownloadFile: except: error, but got: nil\n")
+	}
+	if _, err = os.Stat(fileName); !os.IsNotExist(err) {
+		t.Fatalf("DownloadFile: the file exists before download completed\n")
+	}
+
+	failRange = ""
+	count = 0
+	if err = downloadAPI.DownloadFile("bucket", "object", fileName, opts); err != nil {
+		t.Fatalf("DownloadFile: except: nil, but got: %s\n", err)
+	}
+	// 10 ranges, the first 5 are downloaded
+	if count != 5 {
+		t.Fatalf("DownloadFile: except: %d ranges downloaded, but got: %d\n", 5, count)
+	}
+	var got, _ = ioutil.ReadFile(fileName)
+	if !bytes.Equal(got, data) {
+		t.Fatalf("DownloadFile: the file is not equal to the object\n")
+	}
+	if _, err = os.Stat(opts.Checkpoint); !os.IsNotExist(err) {
+		t.Fatalf("DownloadFile: the checkpoint is not removed\n")
+	}
+}
+
+func TestDownloadFileRangeNotSupported(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "10")
+		w.Write([]byte("0123456789"))
+	}))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var downloadAPI, _ = NewAPI(options)
+
+	var dir, err = ioutil.TempDir("", "oss-go-sdk-download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	err = downloadAPI.DownloadFile("bucket", "object", filepath.Join(dir, "file"), nil)
+	if !errors.Is(err, ErrRangeNotSupported) {
+		t.Fatalf("DownloadFile: except: %s, but got: %v\n", ErrRangeNotSupported, err)
+	}
+}
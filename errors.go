@@ -1,7 +1,14 @@
 package oss
 
 import (
+	"context"
 	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
 )
 
 // Error Each error return by OSS server
@@ -17,19 +24,148 @@ type Error struct {
 	RequestID string `xml:"RequestId"`
 	// OSS cluster name for this request
 	HostID string `xml:"HostId"`
+	// the HTTP status code of response
+	StatusCode int `xml:"-"`
+	// the method, bucket and object of the failed request
+	Method string `xml:"-"`
+	Bucket string `xml:"-"`
+	Object string `xml:"-"`
 	// error xml return by OSS server
 	Raw []byte `xml:"-"`
 }
 
-// Error returns the underlying error's message.
+// Error returns the message with the request and the code of error,
+// e.g.: oss: GET /bucket/object: 404 NoSuchKey: The specified key does not exist. (request id: xxx)
 func (e *Error) Error() string {
-	return e.Message
+	var msg = "oss: "
+	if e.Method != "" {
+		var resource = "/" + e.Object
+		if e.Bucket != "" {
+			resource = fmt.Sprintf("/%s/%s", e.Bucket, e.Object)
+		}
+		msg += fmt.Sprintf("%s %s: ", e.Method, resource)
+	}
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf("%d ", e.StatusCode)
+	}
+	msg += e.Code
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request id: %s)", e.RequestID)
+	}
+	return msg
 }
 
 // parseError parse the error return by OSS server
 func parseError(errStr []byte) *Error {
 	var err Error
 	xml.Unmarshal(errStr, &err)
+	err.RequestID = strings.TrimSpace(err.RequestID)
+	err.HostID = strings.TrimSpace(err.HostID)
 	err.Raw = errStr
 	return &err
 }
+
+// parseResponseError parse the error response of request, the response of HEAD request has no body,
+// so the code is got from the status code
+func parseResponseError(res *http.Response, errStr []byte, method, bucket, object string) *Error {
+	var err = parseError(errStr)
+	err.StatusCode = res.StatusCode
+	err.Method, err.Bucket, err.Object = method, bucket, object
+	if requestID := res.Header.Get("x-oss-request-id"); requestID != "" {
+		err.RequestID = requestID
+	}
+	if err.Code == "" {
+		err.Code = getStatusErrorCode(res.StatusCode, object)
+	}
+	if err.Message == "" {
+		err.Message = http.StatusText(res.StatusCode)
+	}
+	return err
+}
+
+// getStatusErrorCode get the OSS error code of status code for the response without body
+func getStatusErrorCode(statusCode int, object string) string {
+	switch statusCode {
+	case http.StatusNotModified:
+		return "NotModified"
+	case http.StatusBadRequest:
+		return "InvalidArgument"
+	case http.StatusForbidden:
+		return "AccessDenied"
+	case http.StatusNotFound:
+		if object == "" {
+			return "NoSuchBucket"
+		}
+		return "NoSuchKey"
+	case http.StatusPreconditionFailed:
+		return "PreconditionFailed"
+	case http.StatusRequestedRangeNotSatisfiable:
+		return "InvalidRange"
+	case http.StatusServiceUnavailable:
+		return "ServiceUnavailable"
+	}
+	if statusCode >= 500 {
+		return "InternalError"
+	}
+	return ""
+}
+
+// getErrorCode get the status code and OSS error code of err,
+// it works with the wrapped *Error and DeleteError
+func getErrorCode(err error) (int, string, bool) {
+	var ossErr *Error
+	if errors.As(err, &ossErr) {
+		return ossErr.StatusCode, ossErr.Code, true
+	}
+	var deleteErr DeleteError
+	if errors.As(err, &deleteErr) {
+		return 0, deleteErr.Code, true
+	}
+	return 0, "", false
+}
+
+// IsNotFound report whether err is returned by OSS for the missing bucket, object or upload,
+// e.g.: NoSuchKey, NoSuchBucket, NoSuchUpload
+func IsNotFound(err error) bool {
+	var statusCode, code, ok = getErrorCode(err)
+	return ok && (statusCode == http.StatusNotFound || strings.HasPrefix(code, "NoSuch"))
+}
+
+// IsAccessDenied report whether err is returned by OSS for the request without permission
+func IsAccessDenied(err error) bool {
+	var statusCode, code, ok = getErrorCode(err)
+	return ok && (code == "AccessDenied" || (code == "" && statusCode == http.StatusForbidden))
+}
+
+// IsPreconditionFailed report whether err is returned by OSS for the failed conditions,
+// e.g.: If-Match, If-Unmodified-Since and x-oss-forbid-overwrite
+func IsPreconditionFailed(err error) bool {
+	var statusCode, code, ok = getErrorCode(err)
+	return ok && (statusCode == http.StatusPreconditionFailed || code == "PreconditionFailed" ||
+		code == "FileAlreadyExists")
+}
+
+// IsRetryable report whether the request may succeed if it is sent again,
+// the errors are the transport errors, 5xx, 429 and DefaultRetryCodes
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusCode, code, ok = getErrorCode(err)
+	if ok {
+		if statusCode >= 500 || statusCode == http.StatusTooManyRequests {
+			return true
+		}
+		for _, retryCode := range DefaultRetryCodes {
+			if code == retryCode {
+				return true
+			}
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
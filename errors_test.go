@@ -1,7 +1,13 @@
 package oss
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -32,3 +38,72 @@ func TestParseError(t *testing.T) {
 	fmt.Printf("%v\n", realErr.(*Error).Code)
 	fmt.Printf("error: %s\n", e.Error())
 }
+
+func TestResponseError(t *testing.T) {
+	var api, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("x-oss-request-id", "request-id")
+		switch req.Method {
+		case "HEAD":
+			w.WriteHeader(http.StatusNotFound)
+		case "PUT":
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprintf(w, "<Error><Code>PreconditionFailed</Code><Message>failed</Message></Error>")
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, "<Error><Code>AccessDenied</Code><Message>denied</Message><RequestId>body-id</RequestId></Error>")
+		}
+	})
+	defer closeServer()
+
+	var _, err = api.HeadObject("bucket", "object", nil)
+	var ossErr *Error
+	if !errors.As(fmt.Errorf("wrapped: %w", err), &ossErr) {
+		t.Fatalf("HeadObject: except: *Error, but got: %v\n", err)
+	}
+	if ossErr.StatusCode != http.StatusNotFound || ossErr.Code != "NoSuchKey" || ossErr.RequestID != "request-id" ||
+		ossErr.Method != "HEAD" || ossErr.Bucket != "bucket" || ossErr.Object != "object" {
+		t.Fatalf("HeadObject: got unexcept error: %+v\n", ossErr)
+	}
+	if except := "oss: HEAD /bucket/object: 404 NoSuchKey: Not Found (request id: request-id)"; err.Error() != except {
+		t.Fatalf("HeadObject: except: %s, but got: %s\n", except, err)
+	}
+	if !IsNotFound(fmt.Errorf("wrapped: %w", err)) || IsAccessDenied(err) || IsRetryable(err) {
+		t.Fatalf("IsNotFound: except: true, but got: false\n")
+	}
+
+	err = api.PutObject("bucket", "object", strings.NewReader("data"), nil)
+	if !IsPreconditionFailed(err) || IsNotFound(err) {
+		t.Fatalf("IsPreconditionFailed: except: true, but got: false %v\n", err)
+	}
+	var acl AccessControlPolicy
+	err = api.GetObjectACL("bucket", "object", &acl)
+	if !IsAccessDenied(err) || !errors.As(err, &ossErr) || ossErr.RequestID != "request-id" {
+		t.Fatalf("IsAccessDenied: except: true, but got: false %v\n", err)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	var cases = []struct {
+		err    error
+		except bool
+	}{
+		{nil, false},
+		{errors.New("other"), false},
+		{context.Canceled, false},
+		{io.ErrUnexpectedEOF, true},
+		{&net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, true},
+		{&Error{StatusCode: http.StatusServiceUnavailable, Code: "SlowDown"}, true},
+		{fmt.Errorf("wrapped: %w", &Error{StatusCode: http.StatusTooManyRequests}), true},
+		{&Error{StatusCode: http.StatusBadRequest, Code: "RequestTimeout"}, true},
+		{&Error{StatusCode: http.StatusForbidden, Code: "AccessDenied"}, false},
+		{getDeleteError(&DeleteResult{Errors: []DeleteError{{Key: "a", Code: "InternalError"}}}), true},
+	}
+	for i, c := range cases {
+		if IsRetryable(c.err) != c.except {
+			t.Fatalf("IsRetryable %d: except: %v, but got: %v %v\n", i, c.except, !c.except, c.err)
+		}
+	}
+	if !IsAccessDenied(getDeleteError(&DeleteResult{Errors: []DeleteError{{Key: "a", Code: "AccessDenied"}}})) {
+		t.Fatalf("IsAccessDenied: except: true, but got: false\n")
+	}
+}
@@ -7,9 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
-	"strconv"
 	"time"
 )
 
@@ -57,10 +55,10 @@ func addLine(OSSAPI *oss.API, bucket, bookName, line string) {
 
 	var err error
 
-	var headResult http.Header
+	var headResult *oss.ObjectMeta
 	var contentLength = 0
 	if headResult, err = OSSAPI.HeadObject(bucket, bookName, nil); err == nil {
-		contentLength, _ = strconv.Atoi(headResult.Get("Content-Length"))
+		contentLength = int(headResult.Size)
 	}
 
 	if _, err = OSSAPI.AppendObject(bucket, bookName, contentLength, buf, headers); err != nil {
@@ -8,7 +8,6 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
 )
 
@@ -202,11 +201,11 @@ func main() {
 	log.Printf("GetObjectACL result: %s\n", acl.AccessControlList)
 
 	log.Println("HeadObject")
-	var headResult http.Header
+	var headResult *oss.ObjectMeta
 	if headResult, err = OSSAPI.HeadObject(bucket, object, nil); err != nil {
 		log.Printf("HeadObject Error: %s\n", err)
 	}
-	log.Printf("HeadObject result: %s\n", headResult)
+	log.Printf("HeadObject result: %+v\n", headResult)
 
 	log.Println("DeleteObject")
 	if err = OSSAPI.DeleteObject(bucket, object); err != nil {
@@ -223,22 +222,22 @@ func main() {
 	log.Println("AppendObject")
 	var appendObject = "appendObject.data"
 	var appendObject1 = "appendObject1.data"
-	var etag http.Header
+	var appendResult *oss.ObjectMeta
 	body = bytes.NewBufferString("this is the body")
-	if etag, err = OSSAPI.AppendObject(bucket, appendObject, 0, bufio.NewReader(body), headers); err != nil {
+	if appendResult, err = OSSAPI.AppendObject(bucket, appendObject, 0, bufio.NewReader(body), headers); err != nil {
 		log.Printf("AppendObject Error: %s\n", err)
 	}
-	log.Printf("AppendObject result: %s\n", etag)
+	log.Printf("AppendObject result: %+v\n", appendResult)
 
 	log.Println("AppendObject")
 	fp, err = os.Open("main.go")
 	if err != nil {
 		log.Fatal(err)
 	}
-	if etag, err = OSSAPI.AppendObject(bucket, appendObject, 1, fp, headers); err != nil {
+	if appendResult, err = OSSAPI.AppendObject(bucket, appendObject, 1, fp, headers); err != nil {
 		log.Printf("AppendObject Error: %s\n", err)
 	}
-	log.Printf("AppendObject result: %s\n", etag)
+	log.Printf("AppendObject result: %+v\n", appendResult)
 
 	log.Println("CopyObject")
 	if _, err = OSSAPI.CopyObject(bucket, appendObject, bucket, appendObject1, headers); err != nil {
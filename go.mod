@@ -0,0 +1,3 @@
+module github.com/Lupino/oss-go-sdk
+
+go 1.20
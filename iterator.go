@@ -0,0 +1,357 @@
+package oss
+
+import (
+	"context"
+	"strconv"
+)
+
+// pager NOT public API
+// The paging state shared by the iterators, fetch get the next page and whether there are more pages after it.
+type pager[T any] struct {
+	fetch     func() ([]T, bool, error)
+	page      []T
+	current   T
+	truncated bool
+	err       error
+}
+
+// newPager create a pager, the first page is fetched lazily by next
+func newPager[T any](fetch func() ([]T, bool, error)) pager[T] {
+	return pager[T]{fetch: fetch, truncated: true}
+}
+
+// next move to the next item, the empty pages are skipped
+func (p *pager[T]) next() bool {
+	for len(p.page) == 0 {
+		if p.err != nil || !p.truncated {
+			return false
+		}
+		p.page, p.truncated, p.err = p.fetch()
+	}
+	p.current = p.page[0]
+	p.page = p.page[1:]
+	return true
+}
+
+// hasNextPage report whether the listing is truncated and the marker of next page moves forward,
+// it stops the walk when the server returns no progress, so the same page is never requested forever.
+func hasNextPage(truncated bool, marker, nextMarker string) bool {
+	return truncated && nextMarker != "" && nextMarker != marker
+}
+
+// ListObjectsOptions defined the options of ObjectIterator
+type ListObjectsOptions struct {
+	// list the objects whose key begins with prefix
+	Prefix string
+	// group the keys by delimiter, e.g.: "/"
+	Delimiter string
+	// list the objects after marker
+	Marker string
+	// the objects of each page, default is 0, it means the default of server (100), limit to 1000
+	MaxKeys int
+	// set it to "url" if the keys contain the characters not allowed in XML, they are decoded automatically
+	EncodingType string
+	// HTTP header of each request
+	Headers map[string]string
+}
+
+// ObjectIterator walk all the pages of ListBucket, the keys grouped by Delimiter are collected into Prefixes.
+// It is used as:
+//
+//	var iter = api.ListObjects(bucket, opts)
+//	for iter.Next() {
+//		var object = iter.Object()
+//	}
+//	if err := iter.Err(); err != nil {
+//	}
+//
+// The walk is stopped early by stop calling Next.
+type ObjectIterator struct {
+	pager[Content]
+	api      *API
+	ctx      context.Context
+	bucket   string
+	opts     ListObjectsOptions
+	prefixes []string
+}
+
+// ListObjects create an ObjectIterator of bucket, nil opts means list all the objects.
+// The request is sent lazily by Next.
+func (api *API) ListObjects(bucket string, opts *ListObjectsOptions) *ObjectIterator {
+	return api.ListObjectsWithContext(context.Background(), bucket, opts)
+}
+
+// ListObjectsWithContext is like ListObjects, but with a context to cancel the requests.
+func (api *API) ListObjectsWithContext(ctx context.Context, bucket string, opts *ListObjectsOptions) *ObjectIterator {
+	var iter = &ObjectIterator{api: api, ctx: ctx, bucket: bucket}
+	if opts != nil {
+		iter.opts = *opts
+	}
+	iter.pager = newPager(iter.fetch)
+	return iter
+}
+
+// Next move to the next object, it returns false when all the objects are walked or an error occurred.
+func (iter *ObjectIterator) Next() bool {
+	return iter.next()
+}
+
+// fetch get the next page
+func (iter *ObjectIterator) fetch() ([]Content, bool, error) {
+	var result = ListBucketResult{
+		Prefix:       iter.opts.Prefix,
+		Delimiter:    iter.opts.Delimiter,
+		Marker:       iter.opts.Marker,
+		EncodingType: iter.opts.EncodingType,
+	}
+	if iter.opts.MaxKeys > 0 {
+		result.MaxKeys = strconv.Itoa(iter.opts.MaxKeys)
+	}
+	if err := iter.api.ListBucketWithContext(iter.ctx, iter.bucket, &result, copyMap(iter.opts.Headers)); err != nil {
+		return nil, false, err
+	}
+	iter.prefixes = append(iter.prefixes, result.CommonPrefixes...)
+	var marker = result.NextMarker
+	if marker == "" {
+		// the next page starts after the last key or common prefix of this page
+		marker = iter.opts.Marker
+		if len(result.Contents) > 0 {
+			marker = result.Contents[len(result.Contents)-1].Key
+		}
+		if n := len(result.CommonPrefixes); n > 0 && result.CommonPrefixes[n-1] > marker {
+			marker = result.CommonPrefixes[n-1]
+		}
+	}
+	var truncated = hasNextPage(result.IsTruncated, iter.opts.Marker, marker)
+	iter.opts.Marker = marker
+	return result.Contents, truncated, nil
+}
+
+// Object get the current object
+func (iter *ObjectIterator) Object() Content {
+	return iter.current
+}
+
+// Prefixes get the common prefixes of the pages walked, they are all the common prefixes
+// after Next returns false.
+func (iter *ObjectIterator) Prefixes() []string {
+	return iter.prefixes
+}
+
+// Err get the error which stopped the walk
+func (iter *ObjectIterator) Err() error {
+	return iter.err
+}
+
+// ListObjectsV2Options defined the options of ObjectV2Iterator
+type ListObjectsV2Options struct {
+	// list the objects whose key begins with prefix
+	Prefix string
+	// group the keys by delimiter, e.g.: "/"
+	Delimiter string
+	// list the objects after StartAfter
+	StartAfter string
+	// continue the listing from the NextContinuationToken of ListBucketV2
+	ContinuationToken string
+	// the objects of each page, default is 0, it means the default of server (100), limit to 1000
+	MaxKeys int
+	// return the owner of objects or not
+	FetchOwner bool
+	// set it to "url" if the keys contain the characters not allowed in XML, they are decoded automatically
+	EncodingType string
+	// HTTP header of each request
+	Headers map[string]string
+}
+
+// ObjectV2Iterator walk all the pages of ListBucketV2, it is used like ObjectIterator.
+type ObjectV2Iterator struct {
+	pager[Content]
+	api      *API
+	ctx      context.Context
+	bucket   string
+	opts     ListObjectsV2Options
+	prefixes []string
+}
+
+// ListObjectsV2 create an ObjectV2Iterator of bucket, nil opts means list all the objects.
+// The request is sent lazily by Next.
+func (api *API) ListObjectsV2(bucket string, opts *ListObjectsV2Options) *ObjectV2Iterator {
+	return api.ListObjectsV2WithContext(context.Background(), bucket, opts)
+}
+
+// ListObjectsV2WithContext is like ListObjectsV2, but with a context to cancel the requests.
+func (api *API) ListObjectsV2WithContext(ctx context.Context, bucket string, opts *ListObjectsV2Options) *ObjectV2Iterator {
+	var iter = &ObjectV2Iterator{api: api, ctx: ctx, bucket: bucket}
+	if opts != nil {
+		iter.opts = *opts
+	}
+	iter.pager = newPager(iter.fetch)
+	return iter
+}
+
+// Next move to the next object, it returns false when all the objects are walked or an error occurred.
+func (iter *ObjectV2Iterator) Next() bool {
+	return iter.next()
+}
+
+// fetch get the next page
+func (iter *ObjectV2Iterator) fetch() ([]Content, bool, error) {
+	var result = ListBucketV2Result{
+		Prefix:            iter.opts.Prefix,
+		Delimiter:         iter.opts.Delimiter,
+		StartAfter:        iter.opts.StartAfter,
+		ContinuationToken: iter.opts.ContinuationToken,
+		FetchOwner:        iter.opts.FetchOwner,
+		EncodingType:      iter.opts.EncodingType,
+	}
+	if iter.opts.MaxKeys > 0 {
+		result.MaxKeys = strconv.Itoa(iter.opts.MaxKeys)
+	}
+	if err := iter.api.ListBucketV2WithContext(iter.ctx, iter.bucket, &result, copyMap(iter.opts.Headers)); err != nil {
+		return nil, false, err
+	}
+	iter.prefixes = append(iter.prefixes, result.CommonPrefixes...)
+	var truncated = hasNextPage(result.IsTruncated, iter.opts.ContinuationToken, result.NextContinuationToken)
+	iter.opts.ContinuationToken = result.NextContinuationToken
+	return result.Contents, truncated, nil
+}
+
+// Object get the current object
+func (iter *ObjectV2Iterator) Object() Content {
+	return iter.current
+}
+
+// Prefixes get the common prefixes of the pages walked, they are all the common prefixes
+// after Next returns false.
+func (iter *ObjectV2Iterator) Prefixes() []string {
+	return iter.prefixes
+}
+
+// Err get the error which stopped the walk
+func (iter *ObjectV2Iterator) Err() error {
+	return iter.err
+}
+
+// ListBucketsOptions defined the options of BucketIterator
+type ListBucketsOptions struct {
+	// list the buckets whose name begins with prefix
+	Prefix string
+	// list the buckets after marker
+	Marker string
+	// the buckets of each page, default is 0, it means the default of server (100), limit to 1000
+	MaxKeys int
+	// HTTP header of each request
+	Headers map[string]string
+}
+
+// BucketIterator walk all the pages of ListAllMyBuckets, it is used like ObjectIterator.
+type BucketIterator struct {
+	pager[BucketMeta]
+	api  *API
+	ctx  context.Context
+	opts ListBucketsOptions
+}
+
+// ListBuckets create a BucketIterator, nil opts means list all the buckets.
+// The request is sent lazily by Next.
+func (api *API) ListBuckets(opts *ListBucketsOptions) *BucketIterator {
+	return api.ListBucketsWithContext(context.Background(), opts)
+}
+
+// ListBucketsWithContext is like ListBuckets, but with a context to cancel the requests.
+func (api *API) ListBucketsWithContext(ctx context.Context, opts *ListBucketsOptions) *BucketIterator {
+	var iter = &BucketIterator{api: api, ctx: ctx}
+	if opts != nil {
+		iter.opts = *opts
+	}
+	iter.pager = newPager(iter.fetch)
+	return iter
+}
+
+// Next move to the next bucket, it returns false when all the buckets are walked or an error occurred.
+func (iter *BucketIterator) Next() bool {
+	return iter.next()
+}
+
+// fetch get the next page
+func (iter *BucketIterator) fetch() ([]BucketMeta, bool, error) {
+	var result = ListAllMyBucketsResult{
+		Prefix: iter.opts.Prefix,
+		Marker: iter.opts.Marker,
+	}
+	if iter.opts.MaxKeys > 0 {
+		result.MaxKeys = strconv.Itoa(iter.opts.MaxKeys)
+	}
+	if err := iter.api.ListAllMyBucketsWithContext(iter.ctx, &result, copyMap(iter.opts.Headers)); err != nil {
+		return nil, false, err
+	}
+	var truncated = hasNextPage(result.IsTruncated, iter.opts.Marker, result.NextMarker)
+	iter.opts.Marker = result.NextMarker
+	return result.Buckets, truncated, nil
+}
+
+// Bucket get the current bucket
+func (iter *BucketIterator) Bucket() BucketMeta {
+	return iter.current
+}
+
+// Err get the error which stopped the walk
+func (iter *BucketIterator) Err() error {
+	return iter.err
+}
+
+// UploadIterator walk all the pages of ListMultipartUpload, it is used like ObjectIterator.
+type UploadIterator struct {
+	pager[*MultipartUpload]
+	api    *API
+	ctx    context.Context
+	bucket string
+	opts   ListMultipartUploadOptions
+}
+
+// ListUploads create an UploadIterator of bucket, nil opts means list all the multipart uploads.
+// The Prefix, Delimiter, KeyMarker, UploadIDMarker, MaxUploads and EncodingType of opts are used,
+// opts is never changed. The request is sent lazily by Next.
+func (api *API) ListUploads(bucket string, opts *ListMultipartUploadOptions) *UploadIterator {
+	return api.ListUploadsWithContext(context.Background(), bucket, opts)
+}
+
+// ListUploadsWithContext is like ListUploads, but with a context to cancel the requests.
+func (api *API) ListUploadsWithContext(ctx context.Context, bucket string, opts *ListMultipartUploadOptions) *UploadIterator {
+	var iter = &UploadIterator{api: api, ctx: ctx, bucket: bucket}
+	if opts != nil {
+		iter.opts = *opts
+	}
+	iter.opts.Params = copyMap(iter.opts.Params)
+	iter.pager = newPager(iter.fetch)
+	return iter
+}
+
+// Next move to the next upload, it returns false when all the uploads are walked or an error occurred.
+func (iter *UploadIterator) Next() bool {
+	return iter.next()
+}
+
+// fetch get the next page, it moves forward if either of the markers changes
+func (iter *UploadIterator) fetch() ([]*MultipartUpload, bool, error) {
+	var keyMarker, uploadIDMarker = iter.opts.KeyMarker, iter.opts.UploadIDMarker
+	var uploads, err = iter.api.ListMultipartUploadWithContext(iter.ctx, iter.bucket, &iter.opts)
+	if err != nil {
+		return nil, false, err
+	}
+	var truncated = hasNextPage(iter.opts.IsTruncated, keyMarker+"\n"+uploadIDMarker,
+		iter.opts.NextKeyMarker+"\n"+iter.opts.NextUploadIDMarker)
+	iter.opts.KeyMarker = iter.opts.NextKeyMarker
+	iter.opts.UploadIDMarker = iter.opts.NextUploadIDMarker
+	return uploads, truncated, nil
+}
+
+// Upload get the current upload
+func (iter *UploadIterator) Upload() *MultipartUpload {
+	return iter.current
+}
+
+// Err get the error which stopped the walk
+func (iter *UploadIterator) Err() error {
+	return iter.err
+}
@@ -0,0 +1,286 @@
+package oss
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// listTestServer serve the sorted keys page by page, the marker of each request is recorded
+type listTestServer struct {
+	keys    []string
+	markers []string
+}
+
+func (s *listTestServer) handle(w http.ResponseWriter, req *http.Request) {
+	var query = req.URL.Query()
+	var marker = query.Get("marker")
+	s.markers = append(s.markers, marker)
+	var maxKeys, _ = strconv.Atoi(query.Get("max-keys"))
+	var contents []string
+	var truncated = false
+	for _, key := range s.keys {
+		if key <= marker || !strings.HasPrefix(key, query.Get("prefix")) {
+			continue
+		}
+		if len(contents) == maxKeys {
+			truncated = true
+			break
+		}
+		contents = append(contents, fmt.Sprintf("<Contents><Key>%s</Key></Contents>", key))
+	}
+	fmt.Fprintf(w, "<ListBucketResult><IsTruncated>%v</IsTruncated>%s</ListBucketResult>",
+		truncated, strings.Join(contents, ""))
+}
+
+func TestListObjects(t *testing.T) {
+	var keys = []string{"a/1", "a/2", "a/3", "a/4", "a/5", "b/1"}
+	var server = &listTestServer{keys: keys}
+	var listAPI, closeServer = newRetryTestAPI(t, server.handle)
+	defer closeServer()
+
+	var iter = listAPI.ListObjects("bucket", &ListObjectsOptions{Prefix: "a/", MaxKeys: 2})
+	var got []string
+	for iter.Next() {
+		got = append(got, iter.Object().Key)
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("ListObjects: except: nil, but got: %s\n", err)
+	}
+	if strings.Join(got, ",") != "a/1,a/2,a/3,a/4,a/5" {
+		t.Fatalf("ListObjects: except: %s, but got: %s\n", "a/1,a/2,a/3,a/4,a/5", strings.Join(got, ","))
+	}
+	if strings.Join(server.markers, ",") != ",a/2,a/4" {
+		t.Fatalf("ListObjects: except markers: %s, but got: %s\n", ",a/2,a/4", strings.Join(server.markers, ","))
+	}
+}
+
+func TestListObjectsStopEarly(t *testing.T) {
+	var keys = []string{"1", "2", "3", "4", "5"}
+	var server = &listTestServer{keys: keys}
+	var listAPI, closeServer = newRetryTestAPI(t, server.handle)
+	defer closeServer()
+
+	var iter = listAPI.ListObjects("bucket", &ListObjectsOptions{MaxKeys: 2})
+	for iter.Next() {
+		if iter.Object().Key == "2" {
+			break
+		}
+	}
+	if len(server.markers) != 1 {
+		t.Fatalf("ListObjects: except: %d request, but got: %d\n", 1, len(server.markers))
+	}
+}
+
+func TestListObjectsError(t *testing.T) {
+	var listAPI, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "<Error><Code>AccessDenied</Code></Error>")
+	})
+	defer closeServer()
+
+	var iter = listAPI.ListObjects("bucket", nil)
+	if iter.Next() {
+		t.Fatalf("ListObjects: except: no object, but got: %s\n", iter.Object().Key)
+	}
+	if iter.Err() == nil {
+		t.Fatalf("ListObjects: except: AccessDenied, but got: nil\n")
+	}
+}
+
+func TestListBuckets(t *testing.T) {
+	var pages = map[string]string{
+		"":  "<IsTruncated>true</IsTruncated><NextMarker>b</NextMarker><Buckets><Bucket><Name>a</Name></Bucket><Bucket><Name>b</Name></Bucket></Buckets>",
+		"b": "<IsTruncated>false</IsTruncated><Buckets><Bucket><Name>c</Name></Bucket></Buckets>",
+	}
+	var listAPI, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "<ListAllMyBucketsResult>%s</ListAllMyBucketsResult>", pages[req.URL.Query().Get("marker")])
+	})
+	defer closeServer()
+
+	var iter = listAPI.ListBuckets(nil)
+	var got []string
+	for iter.Next() {
+		got = append(got, iter.Bucket().Name)
+	}
+	if iter.Err() != nil || strings.Join(got, ",") != "a,b,c" {
+		t.Fatalf("ListBuckets: except: %s, but got: %s %v\n", "a,b,c", strings.Join(got, ","), iter.Err())
+	}
+}
+
+func TestListUploads(t *testing.T) {
+	var pages = map[string]string{
+		"": `<IsTruncated>true</IsTruncated><NextKeyMarker>b</NextKeyMarker><NextUploadIdMarker>2</NextUploadIdMarker>
+<Upload><Key>a</Key><UploadId>1</UploadId></Upload><Upload><Key>b</Key><UploadId>2</UploadId></Upload>`,
+		"b2": `<IsTruncated>false</IsTruncated><Upload><Key>b</Key><UploadId>3</UploadId></Upload>`,
+	}
+	var listAPI, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		var query = req.URL.Query()
+		fmt.Fprintf(w, "<ListMultipartUploadsResult><Bucket>bucket</Bucket>%s</ListMultipartUploadsResult>",
+			pages[query.Get("key-marker")+query.Get("upload-id-marker")])
+	})
+	defer closeServer()
+
+	var opts = GetDefaultListMultipartUploadOptions()
+	var iter = listAPI.ListUploads("bucket", opts)
+	var got []string
+	for iter.Next() {
+		got = append(got, iter.Upload().Key+iter.Upload().UploadID)
+	}
+	if iter.Err() != nil || strings.Join(got, ",") != "a1,b2,b3" {
+		t.Fatalf("ListUploads: except: %s, but got: %s %v\n", "a1,b2,b3", strings.Join(got, ","), iter.Err())
+	}
+	if opts.KeyMarker != "" || len(opts.Params) != 0 {
+		t.Fatalf("ListUploads: the options is changed: %+v\n", opts)
+	}
+}
+
+func TestListBucketEncodingType(t *testing.T) {
+	var listAPI, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("encoding-type") != "url" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `<ListBucketResult><EncodingType>url</EncodingType><Prefix>fun%2F</Prefix>
+<Delimiter>%2F</Delimiter><IsTruncated>true</IsTruncated><NextMarker>fun%2Fmovie%2F</NextMarker>
+<Contents><Key>fun%2Fa%01b.jpg</Key></Contents>
+<CommonPrefixes><Prefix>fun%2Fimage%2F</Prefix></CommonPrefixes>
+<CommonPrefixes><Prefix>fun%2Fmovie%2F</Prefix></CommonPrefixes>
+</ListBucketResult>`)
+	})
+	defer closeServer()
+
+	var result = ListBucketResult{Prefix: "fun/", Delimiter: "/", EncodingType: "url"}
+	if err := listAPI.ListBucket("bucket", &result, nil); err != nil {
+		t.Fatalf("ListBucket: except: nil, but got: %s\n", err)
+	}
+	if len(result.Contents) != 1 || result.Contents[0].Key != "fun/a\x01b.jpg" {
+		t.Fatalf("ListBucket: except: %q, but got: %+v\n", "fun/a\x01b.jpg", result.Contents)
+	}
+	if strings.Join(result.CommonPrefixes, ",") != "fun/image/,fun/movie/" {
+		t.Fatalf("ListBucket: except: %s, but got: %s\n", "fun/image/,fun/movie/", strings.Join(result.CommonPrefixes, ","))
+	}
+	if result.NextMarker != "fun/movie/" || result.Prefix != "fun/" || result.Delimiter != "/" {
+		t.Fatalf("ListBucket: got undecoded result: %+v\n", result)
+	}
+}
+
+func TestListBucketEncodingTypeNotEchoed(t *testing.T) {
+	var echo bool
+	var listAPI, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("prefix") != "a%2Fb/" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if echo {
+			fmt.Fprint(w, `<ListBucketResult><EncodingType>url</EncodingType><Prefix>a%252Fb%2F</Prefix>
+<Contents><Key>a%252Fb%2Fc</Key></Contents></ListBucketResult>`)
+			return
+		}
+		fmt.Fprint(w, `<ListBucketResult><EncodingType>url</EncodingType>
+<Contents><Key>a%252Fb%2Fc</Key></Contents></ListBucketResult>`)
+	})
+	defer closeServer()
+
+	for _, echo = range []bool{false, true} {
+		var result = ListBucketResult{Prefix: "a%2Fb/", EncodingType: "url"}
+		if err := listAPI.ListBucket("bucket", &result, nil); err != nil {
+			t.Fatalf("ListBucket: except: nil, but got: %s\n", err)
+		}
+		if result.Prefix != "a%2Fb/" || len(result.Contents) != 1 || result.Contents[0].Key != "a%2Fb/c" {
+			t.Fatalf("ListBucket: except: prefix %s and key %s, but got: %+v\n", "a%2Fb/", "a%2Fb/c", result)
+		}
+	}
+
+	// the prefix not echoed is not decoded by ListBucketV2 either
+	var result = ListBucketV2Result{Prefix: "a%2Fb/", EncodingType: "url"}
+	echo = false
+	if err := listAPI.ListBucketV2("bucket", &result, nil); err != nil {
+		t.Fatalf("ListBucketV2: except: nil, but got: %s\n", err)
+	}
+	if result.Prefix != "a%2Fb/" {
+		t.Fatalf("ListBucketV2: except: %s, but got: %s\n", "a%2Fb/", result.Prefix)
+	}
+}
+
+func TestListObjectsDelimiter(t *testing.T) {
+	var pages = map[string]string{
+		"": `<IsTruncated>true</IsTruncated><NextMarker>b/</NextMarker><Contents><Key>a.jpg</Key></Contents>
+<CommonPrefixes><Prefix>a/</Prefix></CommonPrefixes><CommonPrefixes><Prefix>b/</Prefix></CommonPrefixes>`,
+		"b/": `<IsTruncated>false</IsTruncated><CommonPrefixes><Prefix>c/</Prefix></CommonPrefixes>`,
+	}
+	var listAPI, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "<ListBucketResult>%s</ListBucketResult>", pages[req.URL.Query().Get("marker")])
+	})
+	defer closeServer()
+
+	var iter = listAPI.ListObjects("bucket", &ListObjectsOptions{Delimiter: "/"})
+	var got []string
+	for iter.Next() {
+		got = append(got, iter.Object().Key)
+	}
+	if iter.Err() != nil || strings.Join(got, ",") != "a.jpg" {
+		t.Fatalf("ListObjects: except: %s, but got: %s %v\n", "a.jpg", strings.Join(got, ","), iter.Err())
+	}
+	if strings.Join(iter.Prefixes(), ",") != "a/,b/,c/" {
+		t.Fatalf("ListObjects: except: %s, but got: %s\n", "a/,b/,c/", strings.Join(iter.Prefixes(), ","))
+	}
+}
+
+func TestListObjectsV2(t *testing.T) {
+	var pages = map[string]string{
+		"": `<IsTruncated>true</IsTruncated><KeyCount>2</KeyCount><NextContinuationToken>token-1</NextContinuationToken>
+<Contents><Key>a%2F1</Key><Owner><ID>owner</ID></Owner></Contents><Contents><Key>a%2F2</Key></Contents>`,
+		"token-1": `<IsTruncated>false</IsTruncated><KeyCount>1</KeyCount><Contents><Key>a%2F3</Key></Contents>`,
+	}
+	var queries []string
+	var listAPI, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		var query = req.URL.Query()
+		queries = append(queries, req.URL.RawQuery)
+		if query.Get("list-type") != "2" || query.Get("fetch-owner") != "true" ||
+			query.Get("start-after") != "a/0" || query.Get("max-keys") != "2" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "<ListBucketResult><EncodingType>url</EncodingType>%s</ListBucketResult>",
+			pages[query.Get("continuation-token")])
+	})
+	defer closeServer()
+
+	var opts = &ListObjectsV2Options{StartAfter: "a/0", MaxKeys: 2, FetchOwner: true, EncodingType: "url"}
+	var iter = listAPI.ListObjectsV2("bucket", opts)
+	var got []string
+	for iter.Next() {
+		got = append(got, iter.Object().Key)
+	}
+	if iter.Err() != nil || strings.Join(got, ",") != "a/1,a/2,a/3" {
+		t.Fatalf("ListObjectsV2: except: %s, but got: %s %v %v\n", "a/1,a/2,a/3", strings.Join(got, ","), iter.Err(), queries)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("ListObjectsV2: except: %d requests, but got: %d\n", 2, len(queries))
+	}
+}
+
+func TestListBucketV2(t *testing.T) {
+	var listAPI, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := req.URL.Query()["fetch-owner"]; ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `<ListBucketResult><Name>bucket</Name><IsTruncated>true</IsTruncated><KeyCount>2</KeyCount>
+<NextContinuationToken>token-1</NextContinuationToken><Contents><Key>a.jpg</Key></Contents>
+<CommonPrefixes><Prefix>a/</Prefix></CommonPrefixes></ListBucketResult>`)
+	})
+	defer closeServer()
+
+	var result = ListBucketV2Result{Delimiter: "/"}
+	if err := listAPI.ListBucketV2("bucket", &result, nil); err != nil {
+		t.Fatalf("ListBucketV2: except: nil, but got: %s\n", err)
+	}
+	if result.KeyCount != 2 || result.NextContinuationToken != "token-1" || !result.IsTruncated ||
+		len(result.Contents) != 1 || len(result.CommonPrefixes) != 1 {
+		t.Fatalf("ListBucketV2: got unexcept result: %+v\n", result)
+	}
+}
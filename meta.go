@@ -0,0 +1,83 @@
+package oss
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ObjectType defined the type of object
+type ObjectType string
+
+const (
+	// ObjectTypeNormal defined object uploaded by PutObject
+	ObjectTypeNormal ObjectType = "Normal"
+	// ObjectTypeAppendable defined object uploaded by AppendObject
+	ObjectTypeAppendable ObjectType = "Appendable"
+	// ObjectTypeMultipart defined object uploaded by multipart upload
+	ObjectTypeMultipart ObjectType = "Multipart"
+)
+
+// UserMetaPrefix defined the header prefix of user meta
+const UserMetaPrefix = "x-oss-meta-"
+
+// ObjectMeta defined the meta info of object returned in headers
+type ObjectMeta struct {
+	// Content-Length
+	Size int64
+	// ETag with quotes, e.g.: "5B3C1A2E053D763E1B002CC607C5A0FE"
+	ETag               string
+	LastModified       time.Time
+	ContentType        string
+	ContentEncoding    string
+	ContentDisposition string
+	CacheControl       string
+	Expires            string
+	// e.g.: Standard, IA, Archive
+	StorageClass string
+	ObjectType   ObjectType
+	// the position of next AppendObject, only for appendable object
+	NextAppendPosition int64
+	// CRC64 (ECMA) of the object
+	CRC64 uint64
+	// server side encryption, e.g.: AES256, KMS
+	ServerSideEncryption      string
+	ServerSideEncryptionKeyID string
+	// uuid for this request
+	RequestID string
+	// x-oss-meta-* headers without the prefix, the keys are lower case
+	UserMeta map[string]string
+	// the raw headers
+	Header http.Header
+}
+
+// ParseObjectMeta parse the object meta from response headers
+func ParseObjectMeta(header http.Header) *ObjectMeta {
+	var meta = &ObjectMeta{
+		ETag:                      header.Get("ETag"),
+		ContentType:               header.Get("Content-Type"),
+		ContentEncoding:           header.Get("Content-Encoding"),
+		ContentDisposition:        header.Get("Content-Disposition"),
+		CacheControl:              header.Get("Cache-Control"),
+		Expires:                   header.Get("Expires"),
+		StorageClass:              header.Get("x-oss-storage-class"),
+		ObjectType:                ObjectType(header.Get("x-oss-object-type")),
+		ServerSideEncryption:      header.Get("x-oss-server-side-encryption"),
+		ServerSideEncryptionKeyID: header.Get("x-oss-server-side-encryption-key-id"),
+		RequestID:                 header.Get("x-oss-request-id"),
+		UserMeta:                  make(map[string]string),
+		Header:                    header,
+	}
+	meta.Size, _ = strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	meta.NextAppendPosition, _ = strconv.ParseInt(header.Get("x-oss-next-append-position"), 10, 64)
+	meta.CRC64, _ = strconv.ParseUint(header.Get("x-oss-hash-crc64ecma"), 10, 64)
+	meta.LastModified, _ = http.ParseTime(header.Get("Last-Modified"))
+	for k, v := range header {
+		var lower = strings.ToLower(k)
+		if strings.HasPrefix(lower, UserMetaPrefix) && len(v) > 0 {
+			meta.UserMeta[lower[len(UserMetaPrefix):]] = v[0]
+		}
+	}
+	return meta
+}
@@ -0,0 +1,86 @@
+package oss
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseObjectMeta(t *testing.T) {
+	var header = make(http.Header)
+	header.Set("Content-Length", "1024")
+	header.Set("ETag", `"5B3C1A2E053D763E1B002CC607C5A0FE"`)
+	header.Set("Last-Modified", "Fri, 24 Feb 2012 06:07:48 GMT")
+	header.Set("Content-Type", "text/plain")
+	header.Set("x-oss-storage-class", "IA")
+	header.Set("x-oss-object-type", "Appendable")
+	header.Set("x-oss-next-append-position", "1024")
+	header.Set("x-oss-hash-crc64ecma", "14741617095266562575")
+	header.Set("x-oss-server-side-encryption", "KMS")
+	header.Set("x-oss-server-side-encryption-key-id", "key-id")
+	header.Set("x-oss-request-id", "request-id")
+	header.Set("X-Oss-Meta-Author", "lupino")
+
+	var meta = ParseObjectMeta(header)
+	if meta.Size != 1024 {
+		t.Fatalf("Size: except: %d, but got: %d\n", 1024, meta.Size)
+	}
+	if meta.ETag != `"5B3C1A2E053D763E1B002CC607C5A0FE"` {
+		t.Fatalf("ETag: except: %s, but got: %s\n", `"5B3C1A2E053D763E1B002CC607C5A0FE"`, meta.ETag)
+	}
+	var lastModified = time.Date(2012, 2, 24, 6, 7, 48, 0, time.UTC)
+	if !meta.LastModified.Equal(lastModified) {
+		t.Fatalf("LastModified: except: %s, but got: %s\n", lastModified, meta.LastModified)
+	}
+	if meta.ContentType != "text/plain" {
+		t.Fatalf("ContentType: except: %s, but got: %s\n", "text/plain", meta.ContentType)
+	}
+	if meta.StorageClass != "IA" {
+		t.Fatalf("StorageClass: except: %s, but got: %s\n", "IA", meta.StorageClass)
+	}
+	if meta.ObjectType != ObjectTypeAppendable {
+		t.Fatalf("ObjectType: except: %s, but got: %s\n", ObjectTypeAppendable, meta.ObjectType)
+	}
+	if meta.NextAppendPosition != 1024 {
+		t.Fatalf("NextAppendPosition: except: %d, but got: %d\n", 1024, meta.NextAppendPosition)
+	}
+	if meta.CRC64 != 14741617095266562575 {
+		t.Fatalf("CRC64: except: %d, but got: %d\n", uint64(14741617095266562575), meta.CRC64)
+	}
+	if meta.ServerSideEncryption != "KMS" || meta.ServerSideEncryptionKeyID != "key-id" {
+		t.Fatalf("ServerSideEncryption: except: KMS key-id, but got: %s %s\n",
+			meta.ServerSideEncryption, meta.ServerSideEncryptionKeyID)
+	}
+	if meta.RequestID != "request-id" {
+		t.Fatalf("RequestID: except: %s, but got: %s\n", "request-id", meta.RequestID)
+	}
+	if len(meta.UserMeta) != 1 || meta.UserMeta["author"] != "lupino" {
+		t.Fatalf("UserMeta: except: map[author:lupino], but got: %v\n", meta.UserMeta)
+	}
+}
+
+func TestHeadObjectMeta(t *testing.T) {
+	var gotHeader string
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("If-Match")
+		w.Header().Set("Content-Length", "11")
+		w.Header().Set("x-oss-object-type", "Normal")
+		w.Header().Set("x-oss-meta-key", "value")
+	}))
+	defer ts.Close()
+
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var headAPI, _ = NewAPI(options)
+	var meta, err = headAPI.HeadObject("bucket", "object", map[string]string{"If-Match": "etag"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotHeader != "etag" {
+		t.Fatalf("HeadObject headers: except: %s, but got: %s\n", "etag", gotHeader)
+	}
+	if meta.Size != 11 || meta.ObjectType != ObjectTypeNormal || meta.UserMeta["key"] != "value" {
+		t.Fatalf("HeadObject: got unexcept meta: %+v\n", meta)
+	}
+}
@@ -0,0 +1,171 @@
+package oss
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PutObjectOptions defined the typed options of PutObject, AppendObject, CopyObject
+// and NewMultipartUpload, the fields with zero value are ignored.
+//
+//	api.PutObject(bucket, object, body, opts.Headers())
+type PutObjectOptions struct {
+	ContentType        string
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	Expires            time.Time
+	// the acl of object, e.g.: ACLPrivate
+	ACL ACLGrant
+	// e.g.: Standard, IA, Archive
+	StorageClass string
+	// the user meta, the key is without x-oss-meta- prefix
+	UserMeta map[string]string
+	// server side encryption, e.g.: AES256, KMS
+	ServerSideEncryption string
+	// the KMS key id, only for KMS
+	ServerSideEncryptionKeyID string
+	// the tags of object
+	Tagging map[string]string
+	// do not overwrite the object with the same name
+	ForbidOverwrite bool
+	// the bandwidth of PutObject and AppendObject in bit/s, it is clamped to [MinTrafficLimit, MaxTrafficLimit].
+	// Use UploadOptions.TrafficLimit for multipart upload.
+	TrafficLimit int64
+	// listen the progress of PutObjectWithOptions, it is not a header
+	Progress ProgressListener
+}
+
+// GetDefaultPutObjectOptions get default put object options
+func GetDefaultPutObjectOptions() *PutObjectOptions {
+	return &PutObjectOptions{
+		UserMeta: make(map[string]string),
+		Tagging:  make(map[string]string),
+	}
+}
+
+// Headers translate the options into the headers of request
+func (opts *PutObjectOptions) Headers() map[string]string {
+	var headers = make(map[string]string)
+	setHeader(headers, "Content-Type", opts.ContentType)
+	setHeader(headers, "Cache-Control", opts.CacheControl)
+	setHeader(headers, "Content-Disposition", opts.ContentDisposition)
+	setHeader(headers, "Content-Encoding", opts.ContentEncoding)
+	if !opts.Expires.IsZero() {
+		headers["Expires"] = opts.Expires.UTC().Format(http.TimeFormat)
+	}
+	setHeader(headers, "x-oss-object-acl", string(opts.ACL))
+	setHeader(headers, "x-oss-storage-class", opts.StorageClass)
+	for k, v := range opts.UserMeta {
+		headers[UserMetaPrefix+strings.ToLower(k)] = v
+	}
+	setHeader(headers, "x-oss-server-side-encryption", opts.ServerSideEncryption)
+	setHeader(headers, "x-oss-server-side-encryption-key-id", opts.ServerSideEncryptionKeyID)
+	if len(opts.Tagging) > 0 {
+		var tags = make(url.Values)
+		for k, v := range opts.Tagging {
+			tags.Set(k, v)
+		}
+		headers["x-oss-tagging"] = tags.Encode()
+	}
+	if opts.ForbidOverwrite {
+		headers["x-oss-forbid-overwrite"] = "true"
+	}
+	setTrafficLimit(headers, opts.TrafficLimit)
+	return headers
+}
+
+// GetObjectOptions defined the typed options of GetObject, the fields with zero value are ignored.
+//
+//	api.GetObject(bucket, object, opts.Headers(), opts.Params())
+type GetObjectOptions struct {
+	// e.g.: bytes=0-1023, see SetRange
+	Range string
+	// the conditions of object, the request failed if they are not satisfied
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   time.Time
+	IfUnmodifiedSince time.Time
+	// override the response headers
+	ResponseContentType        string
+	ResponseContentLanguage    string
+	ResponseContentDisposition string
+	ResponseContentEncoding    string
+	ResponseCacheControl       string
+	ResponseExpires            string
+	// image or video process, e.g.: image/resize,w_100
+	Process string
+	// the bandwidth in bit/s, it is clamped to [MinTrafficLimit, MaxTrafficLimit]
+	TrafficLimit int64
+	// listen the progress of GetObjectWithOptions, it is neither a header nor a param
+	Progress ProgressListener
+}
+
+// GetDefaultGetObjectOptions get default get object options
+func GetDefaultGetObjectOptions() *GetObjectOptions {
+	return &GetObjectOptions{}
+}
+
+// SetRange set the range of bytes [start, end], end < 0 means to the end of object
+func (opts *GetObjectOptions) SetRange(start, end int64) {
+	if end < 0 {
+		opts.Range = fmt.Sprintf("bytes=%d-", start)
+	} else {
+		opts.Range = fmt.Sprintf("bytes=%d-%d", start, end)
+	}
+}
+
+// Headers translate the options into the headers of request
+func (opts *GetObjectOptions) Headers() map[string]string {
+	var headers = make(map[string]string)
+	setHeader(headers, "Range", opts.Range)
+	setHeader(headers, "If-Match", opts.IfMatch)
+	setHeader(headers, "If-None-Match", opts.IfNoneMatch)
+	if !opts.IfModifiedSince.IsZero() {
+		headers["If-Modified-Since"] = opts.IfModifiedSince.UTC().Format(http.TimeFormat)
+	}
+	if !opts.IfUnmodifiedSince.IsZero() {
+		headers["If-Unmodified-Since"] = opts.IfUnmodifiedSince.UTC().Format(http.TimeFormat)
+	}
+	setTrafficLimit(headers, opts.TrafficLimit)
+	return headers
+}
+
+// Params translate the options into the query params of request,
+// they are signed as sub resources.
+func (opts *GetObjectOptions) Params() map[string]string {
+	var params = make(map[string]string)
+	setHeader(params, "response-content-type", opts.ResponseContentType)
+	setHeader(params, "response-content-language", opts.ResponseContentLanguage)
+	setHeader(params, "response-content-disposition", opts.ResponseContentDisposition)
+	setHeader(params, "response-content-encoding", opts.ResponseContentEncoding)
+	setHeader(params, "response-cache-control", opts.ResponseCacheControl)
+	setHeader(params, "response-expires", opts.ResponseExpires)
+	setHeader(params, "x-oss-process", opts.Process)
+	return params
+}
+
+// setTrafficLimit set the x-oss-traffic-limit header if limit is positive,
+// OSS rejects the value out of range, so it is clamped to [MinTrafficLimit, MaxTrafficLimit]
+func setTrafficLimit(headers map[string]string, limit int64) {
+	if limit <= 0 {
+		return
+	}
+	if limit < MinTrafficLimit {
+		limit = MinTrafficLimit
+	} else if limit > MaxTrafficLimit {
+		limit = MaxTrafficLimit
+	}
+	headers["x-oss-traffic-limit"] = strconv.FormatInt(limit, 10)
+}
+
+// setHeader set the value into headers if it is not empty
+func setHeader(headers map[string]string, key, value string) {
+	if value != "" {
+		headers[key] = value
+	}
+}
@@ -0,0 +1,119 @@
+package oss
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPutObjectOptionsHeaders(t *testing.T) {
+	var opts = GetDefaultPutObjectOptions()
+	opts.ContentType = "text/plain"
+	opts.Expires = time.Date(2012, 2, 24, 6, 7, 48, 0, time.UTC)
+	opts.ACL = ACLPublicRead
+	opts.StorageClass = "IA"
+	opts.UserMeta["Author"] = "lupino"
+	opts.Tagging["b"] = "2"
+	opts.Tagging["a"] = "x y"
+	opts.ForbidOverwrite = true
+
+	var headers = opts.Headers()
+	var excepts = map[string]string{
+		"Content-Type":           "text/plain",
+		"Expires":                "Fri, 24 Feb 2012 06:07:48 GMT",
+		"x-oss-object-acl":       "public-read",
+		"x-oss-storage-class":    "IA",
+		"x-oss-meta-author":      "lupino",
+		"x-oss-tagging":          "a=x+y&b=2",
+		"x-oss-forbid-overwrite": "true",
+	}
+	if len(headers) != len(excepts) {
+		t.Fatalf("Headers: except: %v, but got: %v\n", excepts, headers)
+	}
+	for k, v := range excepts {
+		if headers[k] != v {
+			t.Fatalf("Headers %s: except: %s, but got: %s\n", k, v, headers[k])
+		}
+	}
+}
+
+func TestGetObjectOptions(t *testing.T) {
+	var opts = GetDefaultGetObjectOptions()
+	opts.SetRange(100, -1)
+	if opts.Range != "bytes=100-" {
+		t.Fatalf("SetRange: except: %s, but got: %s\n", "bytes=100-", opts.Range)
+	}
+	opts.SetRange(0, 99)
+	opts.IfNoneMatch = `"etag"`
+	opts.ResponseContentType = "text/html"
+
+	var headers = opts.Headers()
+	if len(headers) != 2 || headers["Range"] != "bytes=0-99" || headers["If-None-Match"] != `"etag"` {
+		t.Fatalf("Headers: got unexcept headers: %v\n", headers)
+	}
+	var params = opts.Params()
+	if len(params) != 1 || params["response-content-type"] != "text/html" {
+		t.Fatalf("Params: got unexcept params: %v\n", params)
+	}
+}
+
+// verifySignV1 check the Authorization of request with the string to sign built from the wire
+func verifySignV1(req *http.Request, secretAccessKey, resource string) bool {
+	var ossHeaders []string
+	for k := range req.Header {
+		var lower = strings.ToLower(k)
+		if strings.HasPrefix(lower, "x-oss-") {
+			ossHeaders = append(ossHeaders, fmt.Sprintf("%s:%s\n", lower, req.Header.Get(k)))
+		}
+	}
+	sort.Strings(ossHeaders)
+	var stringToSign = fmt.Sprintf("%s\n%s\n%s\n%s\n%s%s", req.Method, req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"), req.Header.Get("Date"), strings.Join(ossHeaders, ""), resource)
+	var h = hmac.New(sha1.New, []byte(secretAccessKey))
+	h.Write([]byte(stringToSign))
+	return req.Header.Get("Authorization") == "OSS id:"+base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func TestObjectOptionsSign(t *testing.T) {
+	var resources = []string{"/bucket/object", "/bucket/object?response-content-type=text/html"}
+	var index int
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !verifySignV1(r, "secret", resources[index]) {
+			w.WriteHeader(http.StatusForbidden)
+		}
+		index++
+	}))
+	defer ts.Close()
+
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	options.AccessID = "id"
+	options.SecretAccessKey = "secret"
+	var signAPI, _ = NewAPI(options)
+
+	var putOpts = GetDefaultPutObjectOptions()
+	putOpts.ContentType = "text/plain"
+	putOpts.UserMeta["Author"] = "lupino"
+	putOpts.Tagging["a"] = "1"
+	var err error
+	if err = signAPI.PutObject("bucket", "object", strings.NewReader("body"), putOpts.Headers()); err != nil {
+		t.Fatalf("PutObject: except: nil, but got: %s\n", err)
+	}
+
+	var getOpts = GetDefaultGetObjectOptions()
+	getOpts.SetRange(0, 1)
+	getOpts.ResponseContentType = "text/html"
+	var reader io.ReadCloser
+	if reader, err = signAPI.GetObject("bucket", "object", getOpts.Headers(), getOpts.Params()); err != nil {
+		t.Fatalf("GetObject: except: nil, but got: %s\n", err)
+	}
+	reader.Close()
+}
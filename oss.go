@@ -28,22 +28,30 @@
 // `oss-go-sdk` implement error return by OSS into `error` interface by `oss.Error`,
 // so you can get error return by OSS server from `oss.Error`, just like:
 //
-//  var realErr = err.(*oss.Error)
+//  var realErr *oss.Error
+//  if errors.As(err, &realErr) {
+//      fmt.Println(realErr.StatusCode, realErr.Code, realErr.RequestID)
+//  }
+//
+// The common errors can be checked by IsNotFound, IsAccessDenied, IsPreconditionFailed and IsRetryable.
 //
 package oss
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
-	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -57,6 +65,23 @@ func init() {
 	AGENT = fmt.Sprintf("aliyun-sdk-go/%s (%s/%s;%s)", Version, runtime.GOOS, runtime.GOARCH, runtime.Version())
 }
 
+// DefaultTransport the http transport shared by all OSS API without a custom one.
+// It keeps more idle connections per host than http.DefaultTransport,
+// because most of the requests go to the same OSS endpoint.
+var DefaultTransport http.RoundTripper = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	ForceAttemptHTTP2:     true,
+	MaxIdleConns:          512,
+	MaxIdleConnsPerHost:   128,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: 1 * time.Second,
+}
+
 // APIOptions the options of OSS API
 type APIOptions struct {
 	// The OSS server host
@@ -68,6 +93,29 @@ type APIOptions struct {
 	SecretAccessKey string
 	IsSecurity      bool
 	StsToken        string
+	// CredentialsProvider provide the credentials to sign requests,
+	// AccessID, SecretAccessKey and StsToken are ignored when it is set.
+	CredentialsProvider CredentialsProvider
+	// Transport the http transport to send requests, default is DefaultTransport
+	Transport http.RoundTripper
+	// RetryPolicy decide how to retry the failed requests, default is GetDefaultRetryPolicy()
+	RetryPolicy RetryPolicy
+	// SignVersion the signature algorithm, default is SignVersionV1
+	SignVersion SignVersion
+	// Region the region of bucket, e.g.: cn-hangzhou, only for SignVersionV4.
+	// It is got from the Host if empty.
+	Region string
+	// AdditionalHeaders the extra headers signed by SignVersionV4, e.g.: host
+	AdditionalHeaders []string
+	// EnableCRC64 stream the body of PutObject, AppendObject and UploadPart without Content-MD5,
+	// the data is checked by the CRC64 returned by OSS instead.
+	EnableCRC64 bool
+	// QPS limit the requests sent per second, including the retries, 0 means no limit
+	QPS float64
+	// UploadBandwidth limit the bytes per second of all the request bodies, 0 means no limit
+	UploadBandwidth int64
+	// DownloadBandwidth limit the bytes per second of all the response bodies, 0 means no limit
+	DownloadBandwidth int64
 }
 
 // GetDefaultAPIOptioins get default api options for OSS API
@@ -80,19 +128,26 @@ func GetDefaultAPIOptioins() *APIOptions {
 
 // API A simple OSS API
 type API struct {
-	host            string
-	port            int
-	accessID        string
-	secretAccessKey string
-	isSecurity      bool
-	retryTimes      int
-	agent           string
-	debug           bool
-	// instance level timeout for all operations, default is 60s
+	host        string
+	port        int
+	credentials CredentialsProvider
+	isSecurity  bool
+	retryPolicy RetryPolicy
+	agent       string
+	debug       bool
+	// instance level timeout of each attempt without progress, default is 60s
 	timeout     time.Duration
 	isOSSDomain bool
-	stsToken    string
 	provider    string
+	client      *http.Client
+	// signature algorithm and options of V4
+	signVersion       SignVersion
+	region            string
+	additionalHeaders []string
+	enableCRC64       bool
+	qpsLimiter        *RateLimiter
+	uploadLimiter     *RateLimiter
+	downloadLimiter   *RateLimiter
 }
 
 // NewAPI initial simple OSS API
@@ -100,16 +155,43 @@ func NewAPI(options *APIOptions) (*API, error) {
 	var api = new(API)
 	api.host = options.Host
 	api.port = options.Port
-	api.accessID = options.AccessID
-	api.secretAccessKey = options.SecretAccessKey
+	api.credentials = options.CredentialsProvider
+	if api.credentials == nil {
+		api.credentials = NewStaticCredentialsProvider(options.AccessID, options.SecretAccessKey, options.StsToken)
+	}
 	api.isSecurity = options.IsSecurity
-	api.retryTimes = 5
+	api.retryPolicy = options.RetryPolicy
+	if api.retryPolicy == nil {
+		api.retryPolicy = GetDefaultRetryPolicy()
+	}
 	api.agent = AGENT
 	api.debug = false
 	api.timeout = 60 * time.Second
 	api.isOSSDomain = false
-	api.stsToken = options.StsToken
 	api.provider = PROVIDER
+	api.signVersion = options.SignVersion
+	if api.signVersion == "" {
+		api.signVersion = SignVersionV1
+	}
+	api.region = options.Region
+	if api.region == "" {
+		api.region = getRegionFromHost(api.host)
+	}
+	api.additionalHeaders = options.AdditionalHeaders
+	api.enableCRC64 = options.EnableCRC64
+	api.SetQPS(options.QPS)
+	api.uploadLimiter = newBandwidthLimiter(options.UploadBandwidth)
+	api.downloadLimiter = newBandwidthLimiter(options.DownloadBandwidth)
+	if api.signVersion == SignVersionV4 && api.region == "" {
+		return nil, fmt.Errorf("Region is required by sign version %s", api.signVersion)
+	}
+	// the timeout is applied by doRequest, the throttled transfers may take longer than it
+	api.client = &http.Client{
+		Transport: options.Transport,
+	}
+	if api.client.Transport == nil {
+		api.client.Transport = DefaultTransport
+	}
 
 	if checkValidHost(api.host, api.port, api.timeout) {
 		return api, nil
@@ -118,19 +200,70 @@ func NewAPI(options *APIOptions) (*API, error) {
 	return nil, fmt.Errorf("Server: %s:%d is not avaliable.", api.host, api.port)
 }
 
-// SetTimeout set timeout for OSS API
+// SetTimeout set timeout for OSS API, each attempt of request is canceled and retried with ErrRequestTimeout
+// when there is no progress of request body, response headers or response body within the timeout.
+// It does not limit the total time of request, so the transfers throttled by SetBandwidth never time out.
 func (api *API) SetTimeout(timeout time.Duration) {
 	api.timeout = timeout
 }
 
+// SetHTTPClient set the http client for OSS API.
+// The client is copied, so SetTimeout and SetTransport never change the origin one.
+// The Timeout of client limits the total time of request, including reading the response body.
+func (api *API) SetHTTPClient(client *http.Client) {
+	var c = *client
+	api.client = &c
+}
+
+// SetTransport set the http transport for OSS API, nil means DefaultTransport
+func (api *API) SetTransport(transport http.RoundTripper) {
+	if transport == nil {
+		transport = DefaultTransport
+	}
+	api.client.Transport = transport
+}
+
 // SetDebug set debug for OSS API
 func (api *API) SetDebug() {
 	api.debug = true
 }
 
-// SetRetryTimes set retry times for OSS API
+// SetRetryTimes set retry times for OSS API, it only works with the DefaultRetryPolicy
 func (api *API) SetRetryTimes(retryTimes int) {
-	api.retryTimes = retryTimes
+	if policy, ok := api.retryPolicy.(*DefaultRetryPolicy); ok {
+		var newPolicy = *policy
+		newPolicy.Attempts = retryTimes
+		api.retryPolicy = &newPolicy
+	}
+}
+
+// SetRetryPolicy set retry policy for OSS API
+func (api *API) SetRetryPolicy(policy RetryPolicy) {
+	api.retryPolicy = policy
+}
+
+// SetCredentialsProvider set credentials provider for OSS API
+func (api *API) SetCredentialsProvider(provider CredentialsProvider) {
+	api.credentials = provider
+}
+
+// SetEnableCRC64 set enable CRC64 for OSS API, see APIOptions.EnableCRC64
+func (api *API) SetEnableCRC64(enableCRC64 bool) {
+	api.enableCRC64 = enableCRC64
+}
+
+// SetQPS set the max requests per second for OSS API, 0 means no limit
+func (api *API) SetQPS(qps float64) {
+	api.qpsLimiter = nil
+	if qps > 0 {
+		api.qpsLimiter = NewRateLimiter(qps, 0)
+	}
+}
+
+// SetBandwidth set the max bytes per second of request and response bodies for OSS API, 0 means no limit
+func (api *API) SetBandwidth(upload, download int64) {
+	api.uploadLimiter = newBandwidthLimiter(upload)
+	api.downloadLimiter = newBandwidthLimiter(download)
 }
 
 // SetIsOSSHost set is oss host for OSS API
@@ -168,55 +301,59 @@ func GetDefaultSignURLOptions() *SignURLOptions {
 }
 
 // SignURLAuthWithExpireTime create the authorization for OSS based on the input method, url, body and headers
+// The options is never changed.
 //
 // Returns:
-//     signature url.
+//     signature url, or empty string when the credentials are unavailable.
 func (api *API) SignURLAuthWithExpireTime(options *SignURLOptions) string {
-	var sendTime = time.Now().Add(options.Timeout).UTC().Format("Mon, 02 Oct 2006 03:04:05 GMT")
-	options.Headers["Date"] = sendTime
-	var authValue = getAssign(api.secretAccessKey, options.Method, options.Headers,
-		options.Resource, nil, api.debug)
-	options.Params["OSSAccessKeyId"] = api.accessID
-	options.Params["Expires"] = sendTime
-	options.Params["Signature"] = authValue
-	var signURL = appendParam(options.URL, options.Params)
+	var creds, err = api.credentials.GetCredentials(context.Background())
+	if err != nil {
+		return ""
+	}
+	var headers = copyMap(options.Headers)
+	var params = copyMap(options.Params)
+	if api.signVersion == SignVersionV4 {
+		var bucket, object = options.Bucket, options.Object
+		if bucket == "" {
+			var path = strings.SplitN(strings.TrimPrefix(options.Resource, "/"), "?", 2)[0]
+			var parts = strings.SplitN(path, "/", 2)
+			bucket = parts[0]
+			if len(parts) == 2 {
+				object = parts[1]
+			}
+		}
+		api.presignV4(creds, options.Method, bucket, object, headers, params, time.Now(), options.Timeout)
+		return appendParam(options.URL, params)
+	}
+	if len(creds.StsToken) > 0 {
+		params["security-token"] = creds.StsToken
+	}
+	var expireTime = strconv.FormatInt(time.Now().Add(options.Timeout).Unix(), 10)
+	headers["Date"] = expireTime
+	var authValue = getAssign(creds.SecretAccessKey, options.Method, headers,
+		options.Resource+getResource(params), nil, api.debug)
+	params["OSSAccessKeyId"] = creds.AccessID
+	params["Expires"] = expireTime
+	params["Signature"] = authValue
+	var signURL = appendParam(options.URL, params)
 	return signURL
 }
 
 // SignURL create the authorization for OSS based on the input method, url, body and headers
+// The options is never changed, see Presign for more options.
 //
 // Returns:
-//     signature url.
+//     signature url, or empty string when the credentials are unavailable.
 func (api *API) SignURL(options *SignURLOptions) string {
-	var sendTime = time.Now().Add(options.Timeout).UTC().Format("Mon, 02 Oct 2006 03:04:05 GMT")
-	options.Headers["Date"] = sendTime
-	var resource = fmt.Sprintf("/%s/%s%s", options.Bucket, options.Object, getResource(options.Params))
-	var authValue = getAssign(api.secretAccessKey, options.Method, options.Headers, resource, nil, api.debug)
-	options.Params["OSSAccessKeyId"] = api.accessID
-	options.Params["Expires"] = sendTime
-	options.Params["Signature"] = authValue
-	var url = ""
-	options.Object = quote(options.Object)
-	var schema = "http"
-	if api.isSecurity {
-		schema = "https"
-	}
-	var host = api.host
-	if api.port != 80 && api.port != 443 {
-		host = fmt.Sprintf("%s:%d", api.host, api.port)
+	var signURL, err = api.Presign(options.Bucket, options.Object, &PresignOptions{
+		Method:  options.Method,
+		Expires: options.Timeout,
+		Headers: options.Headers,
+		Params:  options.Params,
+	})
+	if err != nil {
+		return ""
 	}
-	if isIP(api.host) {
-		url = fmt.Sprintf("%s://%s/%s/%s", schema, host, options.Bucket, options.Object)
-	} else if isOSSHost(api.host, api.isOSSDomain) {
-		if checkBucketValid(options.Bucket) {
-			url = fmt.Sprintf("%s://%s.%s/%s", schema, options.Bucket, host, options.Object)
-		} else {
-			url = fmt.Sprintf("%s://%s/%s/%s", schema, host, options.Bucket, options.Object)
-		}
-	} else {
-		url = fmt.Sprintf("%s://%s/%s", schema, host, options.Object)
-	}
-	var signURL = appendParam(url, options.Params)
 	return signURL
 }
 
@@ -224,20 +361,23 @@ func (api *API) SignURL(options *SignURLOptions) string {
 // Create the authorization for OSS based on header input.
 // it should be put into "Authorization" parameter of header.
 //
+//      - creds: the credentials to sign
 //      - method: one of PUT, GET, DELETE, HEAD
 //      - headers: HTTP header
 //      - resource: path of bucket or object, eg: /bucket/ or /bucket/object
 //
 // Returns:
 //     signature string
-func (api *API) createSignForNormalAuth(method string, headers map[string]string, resource string) string {
-	var authValue = fmt.Sprintf("%s %s:%s", api.provider, api.accessID,
-		getAssign(api.secretAccessKey, method, headers, resource, nil, api.debug))
+func (api *API) createSignForNormalAuth(creds *Credentials, method string, headers map[string]string, resource string) string {
+	var authValue = fmt.Sprintf("%s %s:%s", api.provider, creds.AccessID,
+		getAssign(creds.SecretAccessKey, method, headers, resource, nil, api.debug))
 	return authValue
 }
 
 // requestOptions defined requset options
 type requestOptions struct {
+	// Context cancel the request and the retries
+	Context context.Context
 	// one of PUT, GET, DELETE, HEAD, POST
 	Method string
 	Bucket string
@@ -245,15 +385,21 @@ type requestOptions struct {
 	// HTTP header
 	Headers map[string]string
 	Body    io.Reader
-	Params  map[string]string
+	// the length of Body, -1 means unknown
+	ContentLength int64
+	Params        map[string]string
 	// AutoClose the res.Body
 	AutoClose bool
+	// Progress listen the progress of request body, or response body if there is no request body
+	Progress ProgressListener
 }
 
 // getDefaultRequestOptions get default requrest options
 func getDefaultRequestOptions() *requestOptions {
 	var options = new(requestOptions)
+	options.Context = context.Background()
 	options.Method = "GET"
+	options.ContentLength = -1
 	options.Headers = make(map[string]string)
 	options.Params = make(map[string]string)
 	options.AutoClose = false
@@ -265,6 +411,10 @@ func (api *API) httpRequest(options *requestOptions) (res *http.Response, err er
 
 	var req *http.Request
 	var host string
+	var ctx = options.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
 	if options.Headers == nil {
 		options.Headers = make(map[string]string)
@@ -272,81 +422,179 @@ func (api *API) httpRequest(options *requestOptions) (res *http.Response, err er
 	if options.Params == nil {
 		options.Params = make(map[string]string)
 	}
-	for i := 0; i < api.retryTimes; i++ {
-		var schema = "http://"
-		if api.isSecurity || api.port == 443 {
-			api.isSecurity = true
-			schema = "https://"
-		}
 
-		var resource string
+	var schema = "http://"
+	if api.isSecurity || api.port == 443 {
+		api.isSecurity = true
+		schema = "https://"
+	}
+
+	var resource string
 
-		if len(api.stsToken) > 0 {
-			options.Headers["x-oss-security-token"] = api.stsToken
+	if len(options.Bucket) == 0 {
+		resource = "/"
+		options.Headers["Host"] = api.host
+	} else {
+		options.Headers["Host"] = fmt.Sprintf("%s.%s", options.Bucket, api.host)
+		if !isOSSHost(api.host, api.isOSSDomain) {
+			options.Headers["Host"] = api.host
 		}
+		resource = fmt.Sprintf("/%s/", options.Bucket)
+	}
 
+	resource = fmt.Sprintf("%s%s%s", resource, options.Object, getResource(options.Params))
+	var object = quote(options.Object)
+	var url = fmt.Sprintf("/%s", object)
+	if isIP(api.host) {
+		url = fmt.Sprintf("/%s/%s", options.Bucket, object)
 		if len(options.Bucket) == 0 {
-			resource = "/"
-			options.Headers["Host"] = api.host
-		} else {
-			options.Headers["Host"] = fmt.Sprintf("%s.%s", options.Bucket, api.host)
-			if !isOSSHost(api.host, api.isOSSDomain) {
-				options.Headers["Host"] = api.host
-			}
-			resource = fmt.Sprintf("/%s/", options.Bucket)
+			url = fmt.Sprintf("/%s", object)
 		}
+		options.Headers["Host"] = api.host
+	}
 
-		resource = fmt.Sprintf("%s%s%s", resource, options.Object, getResource(options.Params))
-		options.Object = quote(options.Object)
-		var url = fmt.Sprintf("/%s", options.Object)
-		if isIP(api.host) {
-			url = fmt.Sprintf("/%s/%s", options.Bucket, options.Object)
-			if len(options.Bucket) == 0 {
-				url = fmt.Sprintf("/%s", options.Object)
+	url = appendParam(url, options.Params)
+	if checkBucketValid(options.Bucket) && !isIP(api.host) {
+		host = options.Headers["Host"]
+	} else {
+		host = api.host
+	}
+
+	if api.port != 80 && api.port != 443 {
+		options.Headers["Host"] = fmt.Sprintf("%s:%d", options.Headers["Host"], api.port)
+		host = fmt.Sprintf("%s:%d", host, api.port)
+	}
+
+	// the body is rewound before each retry, a body can not rewind is sent only once
+	var body = options.Body
+	var bodySeeker io.Seeker
+	var bodyOffset int64
+	var length = options.ContentLength
+	if length < 0 {
+		if l, ok := getReaderLength(body); ok {
+			length = l
+		}
+	}
+	var tracker = newProgressTracker(options.Progress, length)
+	if tracker != nil && body != nil {
+		body = &progressReader{reader: body, tracker: tracker}
+		tracker.started()
+	}
+	defer func() {
+		if err != nil {
+			tracker.failed(err)
+		}
+	}()
+	if seeker, ok := body.(io.Seeker); ok {
+		if bodyOffset, err = seeker.Seek(0, io.SeekCurrent); err == nil {
+			bodySeeker = seeker
+		}
+	}
+	// hide the Close method, the transport must not close the body of caller
+	if _, ok := body.(io.Closer); ok {
+		body = struct{ io.Reader }{body}
+	}
+	// the body is rewound by bodySeeker, so the limiter is stateless
+	var uploadLimiter, downloadLimiter = api.uploadLimiter, api.downloadLimiter
+	if uploadLimiter != nil && body != nil {
+		body = &rateLimitReader{reader: body, limiter: uploadLimiter, ctx: ctx}
+	}
+
+	var policy = api.retryPolicy
+	for attempt := 0; ; attempt++ {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		if attempt > 0 && bodySeeker != nil {
+			if _, err = bodySeeker.Seek(bodyOffset, io.SeekStart); err != nil {
+				return nil, err
 			}
-			options.Headers["Host"] = api.host
 		}
 
-		url = appendParam(url, options.Params)
-		options.Headers["Date"] = time.Now().UTC().Format("Mon, 02 Oct 2006 03:04:05 GMT")
-		options.Headers["Authorization"] = api.createSignForNormalAuth(options.Method, options.Headers, resource)
-		options.Headers["User-Agent"] = api.agent
-		if checkBucketValid(options.Bucket) && !isIP(api.host) {
-			host = options.Headers["Host"]
+		// the credentials may be refreshed between retries
+		var creds *Credentials
+		if creds, err = api.credentials.GetCredentials(ctx); err != nil {
+			return nil, err
+		}
+		if len(creds.StsToken) > 0 {
+			options.Headers["x-oss-security-token"] = creds.StsToken
 		} else {
-			host = api.host
+			delete(options.Headers, "x-oss-security-token")
 		}
 
-		if api.port != 80 && api.port != 443 {
-			options.Headers["Host"] = fmt.Sprintf("%s:%s", options.Headers["Host"], api.port)
-			host = fmt.Sprintf("%s:%d", host, api.port)
+		// the request is signed after it gets the token, so the Date is not stale
+		if api.qpsLimiter != nil {
+			if err = api.qpsLimiter.Wait(ctx); err != nil {
+				return nil, err
+			}
 		}
+		var now = time.Now()
+		options.Headers["Date"] = now.UTC().Format(http.TimeFormat)
+		if api.signVersion == SignVersionV4 {
+			options.Headers["Authorization"] = api.createSignV4(creds, options.Method, options.Bucket,
+				options.Object, url, options.Headers, now)
+		} else {
+			options.Headers["Authorization"] = api.createSignForNormalAuth(creds, options.Method, options.Headers, resource)
+		}
+		options.Headers["User-Agent"] = api.agent
 
-		if req, err = http.NewRequest(options.Method, schema+host+url, options.Body); err != nil {
-			continue
+		if req, err = http.NewRequestWithContext(ctx, options.Method, schema+host+url, body); err != nil {
+			return nil, err
 		}
 
 		for k, v := range options.Headers {
 			req.Header.Add(k, v)
 		}
-
-		var client = &http.Client{
-			Timeout: api.timeout,
+		if body != nil && length >= 0 {
+			req.ContentLength = length
+			if length == 0 {
+				req.Body = http.NoBody
+			}
 		}
 
-		if res, err = client.Do(req); err != nil {
-			continue
-		}
-		if res.StatusCode/100 != 2 {
+		if res, err = api.doRequest(req); err == nil {
+			if res.StatusCode/100 == 2 {
+				if options.AutoClose {
+					drainAndClose(res.Body)
+				} else if downloadLimiter != nil {
+					res.Body = &rateLimitReadCloser{
+						rateLimitReader: rateLimitReader{reader: res.Body, limiter: downloadLimiter, ctx: ctx},
+						closer:          res.Body,
+					}
+				}
+				if tracker != nil && body != nil {
+					tracker.completed()
+				} else if tracker != nil {
+					tracker.total = res.ContentLength
+					tracker.started()
+					res.Body = &progressReadCloser{
+						progressReader: progressReader{reader: res.Body, tracker: tracker, finish: true},
+						closer:         res.Body,
+					}
+				}
+				return res, nil
+			}
 			var errStr, _ = ioutil.ReadAll(res.Body)
 			res.Body.Close()
-			err = parseError(errStr)
-		} else if options.AutoClose {
-			res.Body.Close()
+			err = parseResponseError(res, errStr, options.Method, options.Bucket, options.Object)
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if attempt+1 >= policy.MaxAttempts() || (body != nil && bodySeeker == nil) ||
+			!policy.ShouldRetry(attempt, res, err) {
+			return
+		}
+
+		var delay = policy.RetryDelay(attempt, res)
+		if api.debug {
+			log.Printf("%s %s failed: %v, retry after %s\n", options.Method, url, err, delay)
+		}
+		if err = sleepWithContext(ctx, delay); err != nil {
+			return nil, err
 		}
-		break
 	}
-	return
 }
 
 // httpRequestWithUnmarshalXML get http request and xml unmarshal
@@ -358,7 +606,7 @@ func (api *API) httpRequestWithUnmarshalXML(options *requestOptions, result inte
 	if res, err = api.httpRequest(options); err != nil {
 		return err
 	}
-	defer res.Body.Close()
+	defer drainAndClose(res.Body)
 
 	if result != nil {
 		if data, err = ioutil.ReadAll(res.Body); err != nil {
@@ -374,12 +622,23 @@ func (api *API) httpRequestWithUnmarshalXML(options *requestOptions, result inte
 
 // GetService list all buckets of user
 func (api *API) GetService(result *ListAllMyBucketsResult, headers map[string]string) error {
-	return api.ListAllMyBuckets(result, headers)
+	return api.GetServiceWithContext(context.Background(), result, headers)
+}
+
+// GetServiceWithContext is like GetService, but with a context to cancel the request.
+func (api *API) GetServiceWithContext(ctx context.Context, result *ListAllMyBucketsResult, headers map[string]string) error {
+	return api.ListAllMyBucketsWithContext(ctx, result, headers)
 }
 
 // ListAllMyBuckets list all buckets of user
 func (api *API) ListAllMyBuckets(result *ListAllMyBucketsResult, headers map[string]string) error {
+	return api.ListAllMyBucketsWithContext(context.Background(), result, headers)
+}
+
+// ListAllMyBucketsWithContext is like ListAllMyBuckets, but with a context to cancel the request.
+func (api *API) ListAllMyBucketsWithContext(ctx context.Context, result *ListAllMyBucketsResult, headers map[string]string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	if result.Prefix != "" {
 		options.Params["prefix"] = result.Prefix
 	}
@@ -395,7 +654,13 @@ func (api *API) ListAllMyBuckets(result *ListAllMyBucketsResult, headers map[str
 
 // GetBucketACL get the bucket ACL.
 func (api *API) GetBucketACL(bucket string, result *AccessControlPolicy) error {
+	return api.GetBucketACLWithContext(context.Background(), bucket, result)
+}
+
+// GetBucketACLWithContext is like GetBucketACL, but with a context to cancel the request.
+func (api *API) GetBucketACLWithContext(ctx context.Context, bucket string, result *AccessControlPolicy) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Bucket = bucket
 	options.Params["acl"] = ""
 	return api.httpRequestWithUnmarshalXML(options, result)
@@ -403,7 +668,13 @@ func (api *API) GetBucketACL(bucket string, result *AccessControlPolicy) error {
 
 // GetBucketLocation get Location of bucket
 func (api *API) GetBucketLocation(bucket string, result *LocationConstraint) error {
+	return api.GetBucketLocationWithContext(context.Background(), bucket, result)
+}
+
+// GetBucketLocationWithContext is like GetBucketLocation, but with a context to cancel the request.
+func (api *API) GetBucketLocationWithContext(ctx context.Context, bucket string, result *LocationConstraint) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Bucket = bucket
 	options.Params["location"] = ""
 	return api.httpRequestWithUnmarshalXML(options, result)
@@ -411,12 +682,23 @@ func (api *API) GetBucketLocation(bucket string, result *LocationConstraint) err
 
 // GetBucket list object that in bucket
 func (api *API) GetBucket(bucket string, result *ListBucketResult, headers map[string]string) error {
-	return api.ListBucket(bucket, result, headers)
+	return api.GetBucketWithContext(context.Background(), bucket, result, headers)
+}
+
+// GetBucketWithContext is like GetBucket, but with a context to cancel the request.
+func (api *API) GetBucketWithContext(ctx context.Context, bucket string, result *ListBucketResult, headers map[string]string) error {
+	return api.ListBucketWithContext(ctx, bucket, result, headers)
 }
 
 // ListBucket list object that in bucket
 func (api *API) ListBucket(bucket string, result *ListBucketResult, headers map[string]string) error {
+	return api.ListBucketWithContext(context.Background(), bucket, result, headers)
+}
+
+// ListBucketWithContext is like ListBucket, but with a context to cancel the request.
+func (api *API) ListBucketWithContext(ctx context.Context, bucket string, result *ListBucketResult, headers map[string]string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Bucket = bucket
 	options.Method = "GET"
 	options.Params["prefix"] = result.Prefix
@@ -425,12 +707,69 @@ func (api *API) ListBucket(bucket string, result *ListBucketResult, headers map[
 	options.Params["max-keys"] = result.MaxKeys
 	options.Params["encoding-type"] = result.EncodingType
 	options.Headers = headers
-	return api.httpRequestWithUnmarshalXML(options, result)
+	// the result may be reused for the next page, clear the fields of last page
+	result.Contents = nil
+	result.CommonPrefixes = nil
+	result.NextMarker = ""
+	result.IsTruncated = false
+	defer clearEchoFields(&result.Prefix, &result.Marker, &result.Delimiter, &result.EncodingType)()
+	if err := api.httpRequestWithUnmarshalXML(options, result); err != nil {
+		return err
+	}
+	return result.decodeKeys()
+}
+
+// ListBucketV2 list object that in bucket by ListObjectsV2 (list-type=2)
+func (api *API) ListBucketV2(bucket string, result *ListBucketV2Result, headers map[string]string) error {
+	return api.ListBucketV2WithContext(context.Background(), bucket, result, headers)
+}
+
+// ListBucketV2WithContext is like ListBucketV2, but with a context to cancel the request.
+func (api *API) ListBucketV2WithContext(ctx context.Context, bucket string, result *ListBucketV2Result, headers map[string]string) error {
+	var options = getDefaultRequestOptions()
+	options.Context = ctx
+	options.Bucket = bucket
+	options.Method = "GET"
+	options.Params["list-type"] = "2"
+	var params = map[string]string{
+		"prefix":             result.Prefix,
+		"delimiter":          result.Delimiter,
+		"start-after":        result.StartAfter,
+		"continuation-token": result.ContinuationToken,
+		"max-keys":           result.MaxKeys,
+		"encoding-type":      result.EncodingType,
+	}
+	for k, v := range params {
+		if v != "" {
+			options.Params[k] = v
+		}
+	}
+	if result.FetchOwner {
+		options.Params["fetch-owner"] = "true"
+	}
+	options.Headers = headers
+	// the result may be reused for the next page, clear the fields of last page
+	result.Contents = nil
+	result.CommonPrefixes = nil
+	result.NextContinuationToken = ""
+	result.IsTruncated = false
+	result.KeyCount = 0
+	defer clearEchoFields(&result.Prefix, &result.StartAfter, &result.Delimiter, &result.EncodingType)()
+	if err := api.httpRequestWithUnmarshalXML(options, result); err != nil {
+		return err
+	}
+	return result.decodeKeys()
 }
 
 // GetBucketWebsite get bucket website config.
 func (api *API) GetBucketWebsite(bucket string, result *WebsiteConfiguration) error {
+	return api.GetBucketWebsiteWithContext(context.Background(), bucket, result)
+}
+
+// GetBucketWebsiteWithContext is like GetBucketWebsite, but with a context to cancel the request.
+func (api *API) GetBucketWebsiteWithContext(ctx context.Context, bucket string, result *WebsiteConfiguration) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Bucket = bucket
 	options.Params["website"] = ""
 	return api.httpRequestWithUnmarshalXML(options, result)
@@ -438,7 +777,13 @@ func (api *API) GetBucketWebsite(bucket string, result *WebsiteConfiguration) er
 
 // GetBucketReferer get the bucket request Referer white list.
 func (api *API) GetBucketReferer(bucket string, result *RefererConfiguration) error {
+	return api.GetBucketRefererWithContext(context.Background(), bucket, result)
+}
+
+// GetBucketRefererWithContext is like GetBucketReferer, but with a context to cancel the request.
+func (api *API) GetBucketRefererWithContext(ctx context.Context, bucket string, result *RefererConfiguration) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Bucket = bucket
 	options.Params["referer"] = ""
 	return api.httpRequestWithUnmarshalXML(options, result)
@@ -446,7 +791,13 @@ func (api *API) GetBucketReferer(bucket string, result *RefererConfiguration) er
 
 // GetBucketLifecycle get bucket lifecycle
 func (api *API) GetBucketLifecycle(bucket string, result *LifecycleConfiguration) error {
+	return api.GetBucketLifecycleWithContext(context.Background(), bucket, result)
+}
+
+// GetBucketLifecycleWithContext is like GetBucketLifecycle, but with a context to cancel the request.
+func (api *API) GetBucketLifecycleWithContext(ctx context.Context, bucket string, result *LifecycleConfiguration) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Bucket = bucket
 	options.Params["lifecycle"] = ""
 	return api.httpRequestWithUnmarshalXML(options, result)
@@ -454,7 +805,13 @@ func (api *API) GetBucketLifecycle(bucket string, result *LifecycleConfiguration
 
 // GetBucketLogging get bucket logging settings
 func (api *API) GetBucketLogging(bucket string, result *BucketLoggingStatus) error {
+	return api.GetBucketLoggingWithContext(context.Background(), bucket, result)
+}
+
+// GetBucketLoggingWithContext is like GetBucketLogging, but with a context to cancel the request.
+func (api *API) GetBucketLoggingWithContext(ctx context.Context, bucket string, result *BucketLoggingStatus) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Bucket = bucket
 	options.Params["logging"] = ""
 	return api.httpRequestWithUnmarshalXML(options, result)
@@ -462,7 +819,12 @@ func (api *API) GetBucketLogging(bucket string, result *BucketLoggingStatus) err
 
 // CreateBucket create bucket
 func (api *API) CreateBucket(bucket string, acl ACLGrant, headers map[string]string) error {
-	return api.PutBucket(bucket, acl, "", headers)
+	return api.CreateBucketWithContext(context.Background(), bucket, acl, headers)
+}
+
+// CreateBucketWithContext is like CreateBucket, but with a context to cancel the request.
+func (api *API) CreateBucketWithContext(ctx context.Context, bucket string, acl ACLGrant, headers map[string]string) error {
+	return api.PutBucketWithContext(ctx, bucket, acl, "", headers)
 }
 
 // PutBucket create bucket
@@ -472,7 +834,13 @@ func (api *API) CreateBucket(bucket string, acl ACLGrant, headers map[string]str
 //      - location: the bucket data region location, Current available: oss-cn-hangzhou, oss-cn-qingdao, oss-cn-beijing, oss-cn-hongkong and oss-cn-shenzhen If change exists bucket region, will throw BucketAlreadyExistsError. If region value invalid, will throw InvalidLocationConstraintError.
 //      - headers: HTTP header
 func (api *API) PutBucket(bucket string, acl ACLGrant, location string, headers map[string]string) error {
+	return api.PutBucketWithContext(context.Background(), bucket, acl, location, headers)
+}
+
+// PutBucketWithContext is like PutBucket, but with a context to cancel the request.
+func (api *API) PutBucketWithContext(ctx context.Context, bucket string, acl ACLGrant, location string, headers map[string]string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "PUT"
 	options.Bucket = bucket
 	if headers != nil {
@@ -484,14 +852,20 @@ func (api *API) PutBucket(bucket string, acl ACLGrant, location string, headers
 	if location != "" {
 		var config = CreateBucketConfiguration{LocationConstraint: location}
 		var data, _ = xml.Marshal(config)
-		options.Body = bytes.NewBuffer(data)
+		options.Body = bytes.NewReader(data)
 	}
 	return api.httpRequestWithUnmarshalXML(options, nil)
 }
 
 // PutBucketACL create bucket with acl or update bucket acl when bucket is exists
 func (api *API) PutBucketACL(bucket string, acl ACLGrant, headers map[string]string) error {
+	return api.PutBucketACLWithContext(context.Background(), bucket, acl, headers)
+}
+
+// PutBucketACLWithContext is like PutBucketACL, but with a context to cancel the request.
+func (api *API) PutBucketACLWithContext(ctx context.Context, bucket string, acl ACLGrant, headers map[string]string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "PUT"
 	options.Bucket = bucket
 	if headers != nil {
@@ -505,7 +879,13 @@ func (api *API) PutBucketACL(bucket string, acl ACLGrant, headers map[string]str
 // PutBucketLogging update the bucket logging settings.
 // Log file will create every one hour and name format: <prefix><bucket>-YYYY-mm-DD-HH-MM-SS-UniqueString.
 func (api *API) PutBucketLogging(sourcebucket, targetbucket, prefix string) error {
+	return api.PutBucketLoggingWithContext(context.Background(), sourcebucket, targetbucket, prefix)
+}
+
+// PutBucketLoggingWithContext is like PutBucketLogging, but with a context to cancel the request.
+func (api *API) PutBucketLoggingWithContext(ctx context.Context, sourcebucket, targetbucket, prefix string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "PUT"
 	options.Bucket = sourcebucket
 	var status = BucketLoggingStatus{
@@ -513,7 +893,7 @@ func (api *API) PutBucketLogging(sourcebucket, targetbucket, prefix string) erro
 		Prefix: prefix,
 	}
 	var data, _ = xml.Marshal(status)
-	options.Body = bytes.NewBuffer(data)
+	options.Body = bytes.NewReader(data)
 	options.Params["logging"] = ""
 	return api.httpRequestWithUnmarshalXML(options, nil)
 }
@@ -523,7 +903,13 @@ func (api *API) PutBucketLogging(sourcebucket, targetbucket, prefix string) erro
 //      - indexfile: the object that contain index page
 //      - errorfile: the object taht contain error page
 func (api *API) PutBucketWebsite(bucket, indexfile, errorfile string) error {
+	return api.PutBucketWebsiteWithContext(context.Background(), bucket, indexfile, errorfile)
+}
+
+// PutBucketWebsiteWithContext is like PutBucketWebsite, but with a context to cancel the request.
+func (api *API) PutBucketWebsiteWithContext(ctx context.Context, bucket, indexfile, errorfile string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "PUT"
 	options.Bucket = bucket
 	var config = WebsiteConfiguration{
@@ -531,32 +917,44 @@ func (api *API) PutBucketWebsite(bucket, indexfile, errorfile string) error {
 		ErrorKey:    errorfile,
 	}
 	var data, _ = xml.Marshal(config)
-	options.Body = bytes.NewBuffer(data)
+	options.Body = bytes.NewReader(data)
 	options.Params["website"] = ""
 	return api.httpRequestWithUnmarshalXML(options, nil)
 }
 
 // PutBucketLifecycle set the bucket object lifecycle.
 func (api *API) PutBucketLifecycle(bucket string, rule LifecycleRule) error {
+	return api.PutBucketLifecycleWithContext(context.Background(), bucket, rule)
+}
+
+// PutBucketLifecycleWithContext is like PutBucketLifecycle, but with a context to cancel the request.
+func (api *API) PutBucketLifecycleWithContext(ctx context.Context, bucket string, rule LifecycleRule) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "PUT"
 	options.Bucket = bucket
 	var config = LifecycleConfiguration{
 		Rule: rule,
 	}
 	var data, _ = xml.Marshal(config)
-	options.Body = bytes.NewBuffer(data)
+	options.Body = bytes.NewReader(data)
 	options.Params["lifecycle"] = ""
 	return api.httpRequestWithUnmarshalXML(options, nil)
 }
 
 // PutBucketReferer set the bucket request Referer white list.
 func (api *API) PutBucketReferer(bucket string, config RefererConfiguration) error {
+	return api.PutBucketRefererWithContext(context.Background(), bucket, config)
+}
+
+// PutBucketRefererWithContext is like PutBucketReferer, but with a context to cancel the request.
+func (api *API) PutBucketRefererWithContext(ctx context.Context, bucket string, config RefererConfiguration) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "PUT"
 	options.Bucket = bucket
 	var data, _ = xml.Marshal(config)
-	options.Body = bytes.NewBuffer(data)
+	options.Body = bytes.NewReader(data)
 	options.Params["referer"] = ""
 	var base64md5 = getBase64MD5(data)
 	options.Headers["Content-MD5"] = base64md5
@@ -566,7 +964,13 @@ func (api *API) PutBucketReferer(bucket string, config RefererConfiguration) err
 // DeleteBucket delete an empty bucket.
 // If bucket is not empty, will throw BucketNotEmptyError. If bucket is not exists, will throw NoSuchBucketError.
 func (api *API) DeleteBucket(bucket string) error {
+	return api.DeleteBucketWithContext(context.Background(), bucket)
+}
+
+// DeleteBucketWithContext is like DeleteBucket, but with a context to cancel the request.
+func (api *API) DeleteBucketWithContext(ctx context.Context, bucket string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "DELETE"
 	options.Bucket = bucket
 	return api.httpRequestWithUnmarshalXML(options, nil)
@@ -574,7 +978,13 @@ func (api *API) DeleteBucket(bucket string) error {
 
 // DeleteBucketWebsite delete bucket website config
 func (api *API) DeleteBucketWebsite(bucket string) error {
+	return api.DeleteBucketWebsiteWithContext(context.Background(), bucket)
+}
+
+// DeleteBucketWebsiteWithContext is like DeleteBucketWebsite, but with a context to cancel the request.
+func (api *API) DeleteBucketWebsiteWithContext(ctx context.Context, bucket string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "DELETE"
 	options.Bucket = bucket
 	options.Params["website"] = ""
@@ -583,7 +993,13 @@ func (api *API) DeleteBucketWebsite(bucket string) error {
 
 // DeleteBucketLifecycle delete bucket object lifecycle
 func (api *API) DeleteBucketLifecycle(bucket string) error {
+	return api.DeleteBucketLifecycleWithContext(context.Background(), bucket)
+}
+
+// DeleteBucketLifecycleWithContext is like DeleteBucketLifecycle, but with a context to cancel the request.
+func (api *API) DeleteBucketLifecycleWithContext(ctx context.Context, bucket string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "DELETE"
 	options.Bucket = bucket
 	options.Params["lifecycle"] = ""
@@ -592,7 +1008,13 @@ func (api *API) DeleteBucketLifecycle(bucket string) error {
 
 // DeleteBucketLogging delete bucket logging settings
 func (api *API) DeleteBucketLogging(bucket string) error {
+	return api.DeleteBucketLoggingWithContext(context.Background(), bucket)
+}
+
+// DeleteBucketLoggingWithContext is like DeleteBucketLogging, but with a context to cancel the request.
+func (api *API) DeleteBucketLoggingWithContext(ctx context.Context, bucket string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "DELETE"
 	options.Bucket = bucket
 	options.Params["logging"] = ""
@@ -601,7 +1023,13 @@ func (api *API) DeleteBucketLogging(bucket string) error {
 
 // DeleteBucketReferer delete the bucket request Referer white list.
 func (api *API) DeleteBucketReferer(bucket string) error {
+	return api.DeleteBucketRefererWithContext(context.Background(), bucket)
+}
+
+// DeleteBucketRefererWithContext is like DeleteBucketReferer, but with a context to cancel the request.
+func (api *API) DeleteBucketRefererWithContext(ctx context.Context, bucket string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "DELETE"
 	options.Bucket = bucket
 	options.Params["referer"] = ""
@@ -610,11 +1038,38 @@ func (api *API) DeleteBucketReferer(bucket string) error {
 
 // GetObject get an object from the bucket.
 func (api *API) GetObject(bucket, object string, headers, params map[string]string) (io.ReadCloser, error) {
+	return api.GetObjectWithContext(context.Background(), bucket, object, headers, params)
+}
+
+// GetObjectWithContext is like GetObject, but with a context to cancel the request.
+func (api *API) GetObjectWithContext(ctx context.Context, bucket, object string, headers, params map[string]string) (io.ReadCloser, error) {
+	return api.getObject(ctx, bucket, object, headers, params, getProgressListener(ctx, nil))
+}
+
+// GetObjectWithOptions is like GetObject, but with the typed options, the progress is reported to opts.Progress.
+func (api *API) GetObjectWithOptions(bucket, object string, opts *GetObjectOptions) (io.ReadCloser, error) {
+	return api.GetObjectWithOptionsContext(context.Background(), bucket, object, opts)
+}
+
+// GetObjectWithOptionsContext is like GetObjectWithOptions, but with a context to cancel the request.
+func (api *API) GetObjectWithOptionsContext(ctx context.Context, bucket, object string, opts *GetObjectOptions) (io.ReadCloser, error) {
+	if opts == nil {
+		opts = GetDefaultGetObjectOptions()
+	}
+	return api.getObject(ctx, bucket, object, opts.Headers(), opts.Params(), getProgressListener(ctx, opts.Progress))
+}
+
+// getObject NOT public API
+// Get the object, the progress of response body is reported to listener.
+func (api *API) getObject(ctx context.Context, bucket, object string, headers, params map[string]string,
+	listener ProgressListener) (io.ReadCloser, error) {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Bucket = bucket
 	options.Object = object
 	options.Headers = headers
 	options.Params = params
+	options.Progress = listener
 
 	var res *http.Response
 	var err error
@@ -627,7 +1082,13 @@ func (api *API) GetObject(bucket, object string, headers, params map[string]stri
 
 // GetObjectACL get object acl
 func (api *API) GetObjectACL(bucket, object string, result *AccessControlPolicy) error {
+	return api.GetObjectACLWithContext(context.Background(), bucket, object, result)
+}
+
+// GetObjectACLWithContext is like GetObjectACL, but with a context to cancel the request.
+func (api *API) GetObjectACLWithContext(ctx context.Context, bucket, object string, result *AccessControlPolicy) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Bucket = bucket
 	options.Object = object
 	options.Params["acl"] = ""
@@ -635,17 +1096,26 @@ func (api *API) GetObjectACL(bucket, object string, result *AccessControlPolicy)
 }
 
 // HeadObject head an object and get the meta info.
-func (api *API) HeadObject(bucket, object string, headers map[string]string) (result http.Header, err error) {
+func (api *API) HeadObject(bucket, object string, headers map[string]string) (result *ObjectMeta, err error) {
+	return api.HeadObjectWithContext(context.Background(), bucket, object, headers)
+}
+
+// HeadObjectWithContext is like HeadObject, but with a context to cancel the request.
+func (api *API) HeadObjectWithContext(ctx context.Context, bucket, object string, headers map[string]string) (result *ObjectMeta, err error) {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "HEAD"
 	options.Bucket = bucket
 	options.Object = object
+	if headers != nil {
+		options.Headers = headers
+	}
 	options.AutoClose = true
 	var res *http.Response
 	if res, err = api.httpRequest(options); err != nil {
 		return
 	}
-	return res.Header, nil
+	return ParseObjectMeta(res.Header), nil
 }
 
 // PutObject add an object to the bucket.
@@ -655,39 +1125,86 @@ func (api *API) HeadObject(bucket, object string, headers map[string]string) (re
 //      - body: readable object
 //      - headers: HTTP Header
 func (api *API) PutObject(bucket, object string, body io.Reader, headers map[string]string) error {
+	return api.PutObjectWithContext(context.Background(), bucket, object, body, headers)
+}
+
+// PutObjectWithContext is like PutObject, but with a context to cancel the request.
+func (api *API) PutObjectWithContext(ctx context.Context, bucket, object string, body io.Reader, headers map[string]string) error {
+	return api.putObject(ctx, bucket, object, body, headers, getProgressListener(ctx, nil))
+}
+
+// PutObjectWithOptions is like PutObject, but with the typed options, the progress is reported to opts.Progress.
+func (api *API) PutObjectWithOptions(bucket, object string, body io.Reader, opts *PutObjectOptions) error {
+	return api.PutObjectWithOptionsContext(context.Background(), bucket, object, body, opts)
+}
+
+// PutObjectWithOptionsContext is like PutObjectWithOptions, but with a context to cancel the request.
+func (api *API) PutObjectWithOptionsContext(ctx context.Context, bucket, object string, body io.Reader,
+	opts *PutObjectOptions) error {
+	if opts == nil {
+		opts = GetDefaultPutObjectOptions()
+	}
+	return api.putObject(ctx, bucket, object, body, opts.Headers(), getProgressListener(ctx, opts.Progress))
+}
+
+// putObject NOT public API
+// Put the object, the progress of request body is reported to listener.
+func (api *API) putObject(ctx context.Context, bucket, object string, body io.Reader, headers map[string]string,
+	listener ProgressListener) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "PUT"
 	options.Bucket = bucket
 	options.Object = object
-	if headers != nil {
-		options.Headers = headers
-	}
+	// prepareBody sets Content-MD5, the headers of caller must not be changed
+	options.Headers = copyMap(headers)
 
-	if bodySeeker, ok := body.(io.ReadSeeker); ok {
-		options.Headers["Content-MD5"] = getBase64MD5WithReader(bodySeeker)
-		bodySeeker.Seek(0, 0)
-		options.Body = bodySeeker
-	} else {
-		var data, _ = ioutil.ReadAll(body)
-		options.Headers["Content-MD5"] = getBase64MD5(data)
-		options.Body = bytes.NewBuffer(data)
+	var crc, err = api.prepareBody(options, body)
+	if err != nil {
+		return err
 	}
 
 	options.AutoClose = true
+	options.Progress = listener
 
-	var err error
-	_, err = api.httpRequest(options)
-	return err
+	var res *http.Response
+	if res, err = api.httpRequest(options); err != nil {
+		return err
+	}
+	return checkCRC64(crc, res.Header)
 }
 
-// PostObject is same to PutObject, but use POST method, so just alisa to PutObject
+// PostObject add an object to the bucket with a multipart/form-data POST, just like a browser does.
+// The form is signed with a policy of the object in one hour.
+//
+//      - bucket: an exists bucket
+//      - object: object name store on OSS
+//      - body: readable object
+//      - headers: other form fields, e.g.: Content-Type, x-oss-meta-*, success_action_status
 func (api *API) PostObject(bucket, object string, body io.Reader, headers map[string]string) error {
-	return api.PutObject(bucket, object, body, headers)
+	return api.PostObjectWithContext(context.Background(), bucket, object, body, headers)
+}
+
+// PostObjectWithContext is like PostObject, but with a context to cancel the request.
+func (api *API) PostObjectWithContext(ctx context.Context, bucket, object string, body io.Reader, headers map[string]string) error {
+	var policy = GetDefaultPostPolicy()
+	policy.Key = object
+	var form, err = api.SignPostPolicyWithContext(ctx, bucket, policy)
+	if err != nil {
+		return err
+	}
+	return api.SubmitPostFormWithContext(ctx, form, headers, body)
 }
 
 // PutObjectACL update object acl
 func (api *API) PutObjectACL(bucket, object, acl string) error {
+	return api.PutObjectACLWithContext(context.Background(), bucket, object, acl)
+}
+
+// PutObjectACLWithContext is like PutObjectACL, but with a context to cancel the request.
+func (api *API) PutObjectACLWithContext(ctx context.Context, bucket, object, acl string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "PUT"
 	options.Bucket = bucket
 	options.Object = object
@@ -700,8 +1217,15 @@ func (api *API) PutObjectACL(bucket, object, acl string) error {
 
 // CopyObject copy an object from sourceName to name.
 func (api *API) CopyObject(sourceBucket, sourceObject, targetBucket, targetObject string,
+	headers map[string]string) (result CopyObjectResult, err error) {
+	return api.CopyObjectWithContext(context.Background(), sourceBucket, sourceObject, targetBucket, targetObject, headers)
+}
+
+// CopyObjectWithContext is like CopyObject, but with a context to cancel the request.
+func (api *API) CopyObjectWithContext(ctx context.Context, sourceBucket, sourceObject, targetBucket, targetObject string,
 	headers map[string]string) (result CopyObjectResult, err error) {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "PUT"
 	options.Bucket = targetBucket
 	options.Object = targetObject
@@ -716,34 +1240,40 @@ func (api *API) CopyObject(sourceBucket, sourceObject, targetBucket, targetObjec
 
 // AppendObject append data to an appendable object
 func (api *API) AppendObject(bucket, object string, position int, body io.Reader,
-	headers map[string]string) (result http.Header, err error) {
+	headers map[string]string) (result *ObjectMeta, err error) {
+	return api.AppendObjectWithContext(context.Background(), bucket, object, position, body, headers)
+}
+
+// AppendObjectWithContext is like AppendObject, but with a context to cancel the request.
+func (api *API) AppendObjectWithContext(ctx context.Context, bucket, object string, position int, body io.Reader,
+	headers map[string]string) (result *ObjectMeta, err error) {
 
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "POST"
 	options.Bucket = bucket
 	options.Object = object
-	if headers != nil {
-		options.Headers = headers
-	}
+	// prepareBody sets Content-MD5, the headers of caller must not be changed
+	options.Headers = copyMap(headers)
 
-	if bodySeeker, ok := body.(io.ReadSeeker); ok {
-		options.Headers["Content-MD5"] = getBase64MD5WithReader(bodySeeker)
-		bodySeeker.Seek(0, 0)
-		options.Body = bodySeeker
-	} else {
-		var data, _ = ioutil.ReadAll(body)
-		options.Headers["Content-MD5"] = getBase64MD5(data)
-		options.Body = bytes.NewBuffer(data)
+	var crc hash.Hash64
+	if crc, err = api.prepareBody(options, body); err != nil {
+		return
 	}
 	options.Params["append"] = ""
 	options.Params["position"] = strconv.Itoa(position)
 	options.AutoClose = true
+	options.Progress = getProgressListener(ctx, nil)
 	var res *http.Response
 	if res, err = api.httpRequest(options); err != nil {
 		return
 	}
 
-	result = res.Header
+	result = ParseObjectMeta(res.Header)
+	// the x-oss-hash-crc64ecma is the CRC64 of whole object, only check the first append
+	if position == 0 {
+		err = checkCRC64(crc, res.Header)
+	}
 
 	return
 
@@ -751,7 +1281,13 @@ func (api *API) AppendObject(bucket, object string, position int, body io.Reader
 
 // DeleteObject delete an object from the bucket.
 func (api *API) DeleteObject(bucket, object string) error {
+	return api.DeleteObjectWithContext(context.Background(), bucket, object)
+}
+
+// DeleteObjectWithContext is like DeleteObject, but with a context to cancel the request.
+func (api *API) DeleteObjectWithContext(ctx context.Context, bucket, object string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "DELETE"
 	options.Bucket = bucket
 	options.Object = object
@@ -759,8 +1295,15 @@ func (api *API) DeleteObject(bucket, object string) error {
 }
 
 // DeleteObjects delete multi objects in one request.
+// It is limited to MaxDeleteObjects keys, use BatchDeleteObjects for more keys.
 func (api *API) DeleteObjects(bucket string, objects []string, result *DeleteResult) error {
+	return api.DeleteObjectsWithContext(context.Background(), bucket, objects, result)
+}
+
+// DeleteObjectsWithContext is like DeleteObjects, but with a context to cancel the request.
+func (api *API) DeleteObjectsWithContext(ctx context.Context, bucket string, objects []string, result *DeleteResult) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "POST"
 	options.Bucket = bucket
 
@@ -781,7 +1324,7 @@ func (api *API) DeleteObjects(bucket string, objects []string, result *DeleteRes
 
 	var data, _ = xml.Marshal(deleteXML)
 	options.Headers["Content-MD5"] = getBase64MD5(data)
-	options.Body = bytes.NewBuffer(data)
+	options.Body = bytes.NewReader(data)
 	options.Params["delete"] = ""
 	if result != nil {
 		return api.httpRequestWithUnmarshalXML(options, result)
@@ -796,14 +1339,27 @@ type MultipartUpload struct {
 	Key       string
 	UploadID  string
 	Initiated time.Time
+	// the bandwidth of UploadPart in bit/s, it is clamped to [MinTrafficLimit, MaxTrafficLimit]
+	TrafficLimit int64
+	// listen the progress of UploadPart, the listener of ctx is used if it is nil
+	Progress ProgressListener
 }
 
 // NewMultipartUpload initial multipart upload
 func (api *API) NewMultipartUpload(bucket, object string, headers map[string]string) (*MultipartUpload, error) {
+	return api.NewMultipartUploadWithContext(context.Background(), bucket, object, headers)
+}
+
+// NewMultipartUploadWithContext is like NewMultipartUpload, but with a context to cancel the request.
+func (api *API) NewMultipartUploadWithContext(ctx context.Context, bucket, object string, headers map[string]string) (*MultipartUpload, error) {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "POST"
 	options.Bucket = bucket
 	options.Object = object
+	if headers != nil {
+		options.Headers = headers
+	}
 	options.Params["uploads"] = ""
 	var result InitiateMultipartUploadResult
 	if err := api.httpRequestWithUnmarshalXML(options, &result); err != nil {
@@ -829,38 +1385,50 @@ func (api *API) GetMultiPartUpload(bucket, object, uploadID string) (*MultipartU
 
 // UploadPart upload the content of io.Reader as one part.
 func (multi *MultipartUpload) UploadPart(partNumber int, body io.Reader) (string, error) {
+	return multi.UploadPartWithContext(context.Background(), partNumber, body)
+}
+
+// UploadPartWithContext is like UploadPart, but with a context to cancel the request.
+func (multi *MultipartUpload) UploadPartWithContext(ctx context.Context, partNumber int, body io.Reader) (string, error) {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "PUT"
 	options.Bucket = multi.Bucket
 	options.Object = multi.Key
 	options.Params["partNumber"] = strconv.Itoa(partNumber)
 	options.Params["uploadId"] = multi.UploadID
+	setTrafficLimit(options.Headers, multi.TrafficLimit)
 
-	if bodySeeker, ok := body.(io.ReadSeeker); ok {
-		options.Headers["Content-MD5"] = getBase64MD5WithReader(bodySeeker)
-		bodySeeker.Seek(0, 0)
-		options.Body = bodySeeker
-	} else {
-		var data, _ = ioutil.ReadAll(body)
-		options.Headers["Content-MD5"] = getBase64MD5(data)
-		options.Body = bytes.NewBuffer(data)
+	var crc, err = multi.api.prepareBody(options, body)
+	if err != nil {
+		return "", err
 	}
 
 	options.AutoClose = true
+	options.Progress = getProgressListener(ctx, multi.Progress)
 
 	var res *http.Response
-	var err error
 	if res, err = multi.api.httpRequest(options); err != nil {
 		return "", err
 	}
+	if err = checkCRC64(crc, res.Header); err != nil {
+		return "", err
+	}
 	return res.Header.Get("ETag"), nil
 }
 
 // CopyPart upload a part with data copy from srouce object in source bucket
 func (multi *MultipartUpload) CopyPart(sourceBucket, sourceObject string, partNumber int,
 	sourceRange string, headers map[string]string) (string, error) {
+	return multi.CopyPartWithContext(context.Background(), sourceBucket, sourceObject, partNumber, sourceRange, headers)
+}
+
+// CopyPartWithContext is like CopyPart, but with a context to cancel the request.
+func (multi *MultipartUpload) CopyPartWithContext(ctx context.Context, sourceBucket, sourceObject string, partNumber int,
+	sourceRange string, headers map[string]string) (string, error) {
 
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "PUT"
 	options.Bucket = multi.Bucket
 	options.Object = multi.Key
@@ -885,7 +1453,13 @@ func (multi *MultipartUpload) CopyPart(sourceBucket, sourceObject string, partNu
 
 // CompleteUpload finish multiupload and merge all the parts as a object.
 func (multi *MultipartUpload) CompleteUpload(parts []Part, result *CompleteMultipartUploadResult) error {
+	return multi.CompleteUploadWithContext(context.Background(), parts, result)
+}
+
+// CompleteUploadWithContext is like CompleteUpload, but with a context to cancel the request.
+func (multi *MultipartUpload) CompleteUploadWithContext(ctx context.Context, parts []Part, result *CompleteMultipartUploadResult) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "POST"
 	options.Bucket = multi.Bucket
 	options.Object = multi.Key
@@ -894,13 +1468,19 @@ func (multi *MultipartUpload) CompleteUpload(parts []Part, result *CompleteMulti
 		Parts: parts,
 	}
 	var data, _ = xml.Marshal(partXML)
-	options.Body = bytes.NewBuffer(data)
+	options.Body = bytes.NewReader(data)
 	return multi.api.httpRequestWithUnmarshalXML(options, &result)
 }
 
 // AbortUpload cancel multiupload and delete all parts
 func (multi *MultipartUpload) AbortUpload() error {
+	return multi.AbortUploadWithContext(context.Background())
+}
+
+// AbortUploadWithContext is like AbortUpload, but with a context to cancel the request.
+func (multi *MultipartUpload) AbortUploadWithContext(ctx context.Context) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "DELETE"
 	options.Bucket = multi.Bucket
 	options.Object = multi.Key
@@ -921,6 +1501,8 @@ type ListMultipartUploadOptions struct {
 	Prefix             string
 	MaxUploads         string
 	EncodingType       string
+	// set by ListMultipartUpload, more uploads are after NextKeyMarker and NextUploadIDMarker
+	IsTruncated bool
 }
 
 // GetDefaultListMultipartUploadOptions get default list multipart upload options
@@ -932,7 +1514,13 @@ func GetDefaultListMultipartUploadOptions() *ListMultipartUploadOptions {
 
 // ListMultipartUpload list all multipart uploads and their parts
 func (api *API) ListMultipartUpload(bucket string, opts *ListMultipartUploadOptions) ([]*MultipartUpload, error) {
+	return api.ListMultipartUploadWithContext(context.Background(), bucket, opts)
+}
+
+// ListMultipartUploadWithContext is like ListMultipartUpload, but with a context to cancel the request.
+func (api *API) ListMultipartUploadWithContext(ctx context.Context, bucket string, opts *ListMultipartUploadOptions) ([]*MultipartUpload, error) {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "GET"
 	options.Bucket = bucket
 	options.Params = opts.Params
@@ -954,6 +1542,7 @@ func (api *API) ListMultipartUpload(bucket string, opts *ListMultipartUploadOpti
 	opts.Delimiter = result.Delimiter
 	opts.Prefix = result.Prefix
 	opts.MaxUploads = result.MaxUploads
+	opts.IsTruncated = result.IsTruncated
 	var uploads = make([]*MultipartUpload, len(result.Uploads))
 
 	for id, v := range result.Uploads {
@@ -971,29 +1560,54 @@ func (api *API) ListMultipartUpload(bucket string, opts *ListMultipartUploadOpti
 
 // ListParts list all upload parts of current upload_id
 func (multi *MultipartUpload) ListParts(maxParts, partNumberMarker int,
+	result *ListPartsResult) error {
+	return multi.ListPartsWithContext(context.Background(), maxParts, partNumberMarker, result)
+}
+
+// ListPartsWithContext is like ListParts, but with a context to cancel the request.
+func (multi *MultipartUpload) ListPartsWithContext(ctx context.Context, maxParts, partNumberMarker int,
 	result *ListPartsResult) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "GET"
 	options.Bucket = multi.Bucket
 	options.Object = multi.Key
 	options.Params["uploadId"] = multi.UploadID
+	if maxParts > 0 {
+		options.Params["max-parts"] = strconv.Itoa(maxParts)
+	}
+	if partNumberMarker > 0 {
+		options.Params["part-number-marker"] = strconv.Itoa(partNumberMarker)
+	}
 	return multi.api.httpRequestWithUnmarshalXML(options, &result)
 }
 
 // PutBucketCORS put bucket cors
 func (api *API) PutBucketCORS(bucket string, config CORSConfiguration) error {
+	return api.PutBucketCORSWithContext(context.Background(), bucket, config)
+}
+
+// PutBucketCORSWithContext is like PutBucketCORS, but with a context to cancel the request.
+func (api *API) PutBucketCORSWithContext(ctx context.Context, bucket string, config CORSConfiguration) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "PUT"
 	options.Bucket = bucket
 	var data, _ = xml.Marshal(config)
-	options.Body = bytes.NewBuffer(data)
+	options.Body = bytes.NewReader(data)
 	options.Params["cors"] = ""
 	return api.httpRequestWithUnmarshalXML(options, nil)
 }
 
 // GetBucketCORS Get bucket cors
 func (api *API) GetBucketCORS(bucket string, result *CORSConfiguration) error {
+	return api.GetBucketCORSWithContext(context.Background(), bucket, result)
+}
+
+// GetBucketCORSWithContext is like GetBucketCORS, but with a context to cancel the request.
+func (api *API) GetBucketCORSWithContext(ctx context.Context, bucket string, result *CORSConfiguration) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Bucket = bucket
 	options.Params["cors"] = ""
 	return api.httpRequestWithUnmarshalXML(options, result)
@@ -1001,7 +1615,13 @@ func (api *API) GetBucketCORS(bucket string, result *CORSConfiguration) error {
 
 // DeleteBucketCORS Delete bucket cors
 func (api *API) DeleteBucketCORS(bucket string) error {
+	return api.DeleteBucketCORSWithContext(context.Background(), bucket)
+}
+
+// DeleteBucketCORSWithContext is like DeleteBucketCORS, but with a context to cancel the request.
+func (api *API) DeleteBucketCORSWithContext(ctx context.Context, bucket string) error {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "DELETE"
 	options.Bucket = bucket
 	options.Params["cors"] = ""
@@ -1009,8 +1629,14 @@ func (api *API) DeleteBucketCORS(bucket string) error {
 }
 
 // OptionObject options object to determine if user can send the actual HTTP request
-func (api *API) OptionObject(bucket, object string, headers map[string]string) (http.Header, error) {
+func (api *API) OptionObject(bucket, object string, headers map[string]string) (*ObjectMeta, error) {
+	return api.OptionObjectWithContext(context.Background(), bucket, object, headers)
+}
+
+// OptionObjectWithContext is like OptionObject, but with a context to cancel the request.
+func (api *API) OptionObjectWithContext(ctx context.Context, bucket, object string, headers map[string]string) (*ObjectMeta, error) {
 	var options = getDefaultRequestOptions()
+	options.Context = ctx
 	options.Method = "OPTIONS"
 	options.Bucket = bucket
 	options.Object = object
@@ -1021,14 +1647,22 @@ func (api *API) OptionObject(bucket, object string, headers map[string]string) (
 	if res, err = api.httpRequest(options); err != nil {
 		return nil, err
 	}
-	return res.Header, nil
+	return ParseObjectMeta(res.Header), nil
 }
 
 // UploadLargeFile upload large file, the content is read from filename.
 // The large file is splitted into many parts.
-// It will put the many parts into bucket and then merge all the parts into one object.
+// It will put the many parts into bucket parallel and then merge all the parts into one object,
+// see UploadFile for more options.
 func (api *API) UploadLargeFile(bucket, object, fileName string, bufSize int64,
 	headers map[string]string) (result CompleteMultipartUploadResult, err error) {
+	return api.UploadLargeFileWithContext(context.Background(), bucket, object, fileName, bufSize, headers)
+}
+
+// UploadLargeFileWithContext is like UploadLargeFile, but with a context to cancel the upload.
+// When the ctx is canceled the multipart upload is aborted.
+func (api *API) UploadLargeFileWithContext(ctx context.Context, bucket, object, fileName string, bufSize int64,
+	headers map[string]string) (result CompleteMultipartUploadResult, err error) {
 
 	if bufSize < 1024*100 {
 		err = fmt.Errorf("bufSize must big than %d", 1024*100)
@@ -1048,46 +1682,8 @@ func (api *API) UploadLargeFile(bucket, object, fileName string, bufSize int64,
 		err = fmt.Errorf("need a large file, and it's size big than %d\n", bufSize)
 		return
 	}
-	var filePart = int(fileSize / bufSize)
-	if int64(filePart)*bufSize < fileSize {
-		filePart = filePart + 1
-	}
-
-	if api.debug {
-		log.Printf("Upload large file: %s\ntotal part: %d\n", fileName, filePart)
-	}
-
-	var rd io.Reader
-	var parts = make([]Part, filePart)
-	var etag string
-	var uploadFailed = false
-
-	var multi *MultipartUpload
-	if multi, err = api.NewMultipartUpload(bucket, object, headers); err != nil {
-		return
-	}
-
-	for i := 1; i <= filePart; i++ {
-		rd = io.LimitReader(fp, bufSize)
-		if etag, err = multi.UploadPart(i, rd); err != nil {
-			uploadFailed = true
-			break
-		}
-		if api.debug {
-			log.Printf("PartNumber: %d, ETag: %s\n", i, etag)
-		}
-		parts[i-1] = Part{
-			PartNumber: i,
-			ETag:       etag,
-		}
-	}
-
-	if uploadFailed {
-		multi.AbortUpload()
-		err = errors.New("multi upload file failed")
-	} else {
-		err = multi.CompleteUpload(parts, &result)
-	}
-
-	return
+	var opts = GetDefaultUploadOptions()
+	opts.PartSize = bufSize
+	opts.Headers = headers
+	return api.UploadWithContext(ctx, bucket, object, fp, fileSize, opts)
 }
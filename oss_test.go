@@ -3,6 +3,8 @@ package oss
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -13,15 +15,17 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 var api *API
 var options *APIOptions
+var mockServer *httptest.Server
 
 func init() {
-	var ts = mockHTTPServer()
+	mockServer = mockHTTPServer()
 	options = GetDefaultAPIOptioins()
-	options.Host, options.Port = getHostFromURL(ts.URL)
+	options.Host, options.Port = getHostFromURL(mockServer.URL)
 	api, _ = NewAPI(options)
 }
 
@@ -497,3 +501,67 @@ func TestUploadLargeFile(t *testing.T) {
 	api.SetDebug()
 	api.UploadLargeFile("bucket", "object", fileName, 1024*101, nil)
 }
+
+func TestRequestWithContext(t *testing.T) {
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	var err error
+	if _, err = api.GetObjectWithContext(ctx, "bucket", "object", nil, nil); err != context.Canceled {
+		t.Fatalf("GetObjectWithContext: except: %v, but got: %v\n", context.Canceled, err)
+	}
+
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		fmt.Fprintf(w, "success")
+	}))
+	defer ts.Close()
+
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var slowAPI, _ = NewAPI(options)
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err = slowAPI.DeleteObjectWithContext(ctx, "bucket", "object"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("DeleteObjectWithContext: except: %v, but got: %v\n", context.DeadlineExceeded, err)
+	}
+}
+
+type countTransport struct {
+	count int
+}
+
+func (t *countTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.count++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestSetTransport(t *testing.T) {
+	var transport = new(countTransport)
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(mockServer.URL)
+	options.Transport = transport
+	var api, _ = NewAPI(options)
+	if err := api.DeleteObject("bucket", "object"); err != nil {
+		t.Fatal(err)
+	}
+	if transport.count != 1 {
+		t.Fatalf("Transport: except: %d, but got: %d\n", 1, transport.count)
+	}
+
+	var client = &http.Client{Timeout: time.Second}
+	api.SetHTTPClient(client)
+	api.SetTimeout(time.Minute)
+	if client.Timeout != time.Second {
+		t.Fatalf("SetHTTPClient: except: %v, but got: %v\n", time.Second, client.Timeout)
+	}
+	api.SetTransport(transport)
+	if err := api.DeleteObject("bucket", "object"); err != nil {
+		t.Fatal(err)
+	}
+	if transport.count != 2 {
+		t.Fatalf("Transport: except: %d, but got: %d\n", 2, transport.count)
+	}
+	if client.Transport != nil {
+		t.Fatalf("SetTransport: the origin client is changed")
+	}
+}
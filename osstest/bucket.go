@@ -0,0 +1,437 @@
+package osstest
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Lupino/oss-go-sdk"
+)
+
+// bucket defined the state of a bucket
+type bucket struct {
+	name     string
+	location string
+	acl      string
+	created  time.Time
+	objects  map[string]*object
+	uploads  map[string]*upload
+	// the configs of sub resources, e.g.: cors, lifecycle, website, referer, logging
+	configs map[string][]byte
+}
+
+func newBucket(name, location, acl string) *bucket {
+	return &bucket{
+		name:     name,
+		location: location,
+		acl:      acl,
+		created:  time.Now().UTC(),
+		objects:  make(map[string]*object),
+		uploads:  make(map[string]*upload),
+		configs:  make(map[string][]byte),
+	}
+}
+
+// configErrors defined the error of sub resource config not found
+var configErrors = map[string]*Error{
+	"cors":      newError(http.StatusNotFound, "NoSuchCORSConfiguration", "The CORS Configuration does not exist."),
+	"lifecycle": newError(http.StatusNotFound, "NoSuchLifecycle", "No Row found in Lifecycle Table."),
+	"website":   newError(http.StatusNotFound, "NoSuchWebsiteConfiguration", "The specified bucket does not have a website configuration."),
+	"referer":   nil,
+	"logging":   nil,
+}
+
+// canAccess check the anonymous request by ACL
+func canAccess(r *request, acl string) bool {
+	if r.signed {
+		return true
+	}
+	switch r.Method {
+	case "GET", "HEAD":
+		return acl == string(oss.ACLPublicRead) || acl == string(oss.ACLPublicReadWrite)
+	case "PUT", "POST", "DELETE":
+		return acl == string(oss.ACLPublicReadWrite)
+	}
+	return false
+}
+
+// getBucket get the bucket of request, it is checked by ACL if the request is anonymous
+func (s *Server) getBucket(r *request) (*bucket, error) {
+	var b = s.buckets[r.bucket]
+	if b == nil {
+		return nil, errNoSuchBucket
+	}
+	if !canAccess(r, b.acl) {
+		return nil, errAccessDenied
+	}
+	return b, nil
+}
+
+// listBuckets serve ListAllMyBuckets
+func (s *Server) listBuckets(w http.ResponseWriter, r *request) error {
+	if !r.signed {
+		return errAccessDenied
+	}
+	var prefix, marker = r.query.Get("prefix"), r.query.Get("marker")
+	var maxKeys = getMaxKeys(r.query.Get("max-keys"))
+	var names []string
+	for name := range s.buckets {
+		if strings.HasPrefix(name, prefix) && name > marker {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var result = oss.ListAllMyBucketsResult{
+		Prefix:  prefix,
+		Marker:  marker,
+		MaxKeys: strconv.Itoa(maxKeys),
+		Owner:   Owner,
+	}
+	if len(names) > maxKeys {
+		names = names[:maxKeys]
+		result.IsTruncated = true
+		result.NextMarker = names[maxKeys-1]
+	}
+	for _, name := range names {
+		var b = s.buckets[name]
+		result.Buckets = append(result.Buckets, oss.BucketMeta{
+			Location:     b.location,
+			Name:         b.name,
+			CreationDate: b.created,
+		})
+	}
+	return writeXML(w, result)
+}
+
+// serveBucket serve the operations of bucket
+func (s *Server) serveBucket(w http.ResponseWriter, r *request) error {
+	if r.Method == "PUT" && len(r.query) == 0 {
+		return s.putBucket(w, r)
+	}
+	var b, err = s.getBucket(r)
+	if err != nil {
+		return err
+	}
+	// the configs of bucket are only accessed by owner
+	var ownerOnly = r.has("acl") || r.has("location")
+	for name := range configErrors {
+		ownerOnly = ownerOnly || r.has(name)
+	}
+	if ownerOnly && !r.signed {
+		return errAccessDenied
+	}
+
+	switch {
+	case r.has("acl"):
+		return s.serveBucketACL(w, r, b)
+	case r.has("location"):
+		if r.Method != "GET" {
+			return errMethodNotAllowed
+		}
+		return writeXML(w, struct {
+			XMLName  xml.Name `xml:"LocationConstraint"`
+			Location string   `xml:",chardata"`
+		}{Location: b.location})
+	case r.has("uploads"):
+		if r.Method != "GET" {
+			return errMethodNotAllowed
+		}
+		return s.listUploads(w, r, b)
+	case r.has("delete"):
+		if r.Method != "POST" {
+			return errMethodNotAllowed
+		}
+		return s.deleteObjects(w, r, b)
+	}
+	for name := range configErrors {
+		if r.has(name) {
+			return s.serveBucketConfig(w, r, b, name)
+		}
+	}
+
+	switch r.Method {
+	case "GET":
+		if r.query.Get("list-type") == "2" {
+			return s.listObjectsV2(w, r, b)
+		}
+		return s.listObjects(w, r, b)
+	case "DELETE":
+		if len(b.objects) > 0 || len(b.uploads) > 0 {
+			return newError(http.StatusConflict, "BucketNotEmpty", "The bucket you tried to delete is not empty.")
+		}
+		delete(s.buckets, b.name)
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	case "POST":
+		return errNotImplemented
+	}
+	return errMethodNotAllowed
+}
+
+// putBucket create a bucket, or update the ACL of bucket if it exists
+func (s *Server) putBucket(w http.ResponseWriter, r *request) error {
+	if !r.signed {
+		return errAccessDenied
+	}
+	var acl = r.Header.Get("x-oss-acl")
+	if acl != "" && !isValidACL(acl) {
+		return newError(http.StatusBadRequest, "InvalidArgument", "no such bucket access control exists")
+	}
+	if b := s.buckets[r.bucket]; b != nil {
+		if acl != "" {
+			b.acl = acl
+		}
+		return nil
+	}
+	if !isValidBucketName(r.bucket) {
+		return newError(http.StatusBadRequest, "InvalidBucketName", "The specified bucket is not valid.")
+	}
+	var location = s.opts.Location
+	if len(r.body) > 0 {
+		var config oss.CreateBucketConfiguration
+		if err := decodeXML(r, &config); err != nil {
+			return err
+		}
+		if config.LocationConstraint != "" {
+			location = config.LocationConstraint
+		}
+	}
+	if acl == "" {
+		acl = string(oss.ACLPrivate)
+	}
+	s.buckets[r.bucket] = newBucket(r.bucket, location, acl)
+	w.Header().Set("Location", "/"+r.bucket)
+	return nil
+}
+
+// serveBucketACL get or put the ACL of bucket
+func (s *Server) serveBucketACL(w http.ResponseWriter, r *request, b *bucket) error {
+	switch r.Method {
+	case "GET":
+		return writeACL(w, b.acl)
+	case "PUT":
+		var acl = r.Header.Get("x-oss-acl")
+		if !isValidACL(acl) {
+			return newError(http.StatusBadRequest, "InvalidArgument", "no such bucket access control exists")
+		}
+		b.acl = acl
+		return nil
+	}
+	return errMethodNotAllowed
+}
+
+// serveBucketConfig put, get or delete the config of sub resource, e.g.: cors, lifecycle
+func (s *Server) serveBucketConfig(w http.ResponseWriter, r *request, b *bucket, name string) error {
+	switch r.Method {
+	case "GET":
+		var data, ok = b.configs[name]
+		if !ok {
+			if configErrors[name] != nil {
+				return configErrors[name]
+			}
+			data = defaultConfig(name)
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write(data)
+		return nil
+	case "PUT":
+		if err := checkContentMD5(r); err != nil {
+			return err
+		}
+		var root struct {
+			XMLName xml.Name
+		}
+		if err := decodeXML(r, &root); err != nil {
+			return err
+		}
+		b.configs[name] = r.body
+		return nil
+	case "DELETE":
+		delete(b.configs, name)
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	return errMethodNotAllowed
+}
+
+// defaultConfig get the config of sub resource which is not set
+func defaultConfig(name string) []byte {
+	switch name {
+	case "referer":
+		var data, _ = xml.Marshal(oss.RefererConfiguration{AllowEmptyReferer: true})
+		return data
+	case "logging":
+		return []byte("<BucketLoggingStatus></BucketLoggingStatus>")
+	}
+	return nil
+}
+
+// writeACL write the AccessControlPolicy of acl
+func writeACL(w http.ResponseWriter, acl string) error {
+	return writeXML(w, oss.AccessControlPolicy{
+		Owner:             Owner,
+		AccessControlList: []string{acl},
+	})
+}
+
+func isValidACL(acl string) bool {
+	return acl == string(oss.ACLPrivate) || acl == string(oss.ACLPublicRead) || acl == string(oss.ACLPublicReadWrite)
+}
+
+// isValidBucketName check the bucket name: 3 to 63 lowercase letters, numbers or hyphens,
+// begin and end with lowercase letter or number
+func isValidBucketName(name string) bool {
+	if len(name) < 3 || len(name) > 63 || name[0] == '-' || name[len(name)-1] == '-' {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// getMaxKeys get the max keys of listing, default is 100, limit to 1000
+func getMaxKeys(value string) int {
+	var maxKeys, err = strconv.Atoi(value)
+	if err != nil || maxKeys <= 0 {
+		return 100
+	}
+	if maxKeys > 1000 {
+		return 1000
+	}
+	return maxKeys
+}
+
+// listEntry defined an object or a common prefix of listing
+type listEntry struct {
+	key    string
+	object *object
+}
+
+// listKeys list the objects after marker grouped by delimiter, at most maxKeys objects and common prefixes.
+func (b *bucket) listKeys(prefix, delimiter, marker string, maxKeys int) (entries []listEntry, truncated bool) {
+	var keys []string
+	for key := range b.objects {
+		if strings.HasPrefix(key, prefix) && key > marker {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var entry = listEntry{key: key, object: b.objects[key]}
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				entry = listEntry{key: key[:len(prefix)+i+len(delimiter)]}
+				// the keys of common prefix are skipped, the marker may be in the common prefix
+				if entry.key <= marker || (len(entries) > 0 && entries[len(entries)-1].key == entry.key) {
+					continue
+				}
+			}
+		}
+		if len(entries) == maxKeys {
+			return entries, true
+		}
+		entries = append(entries, entry)
+	}
+	return entries, false
+}
+
+// content get the Content of listing
+func (o *object) content(key string, owner bool) oss.Content {
+	var content = oss.Content{
+		Key:          key,
+		LastModified: o.modified,
+		ETag:         o.etag,
+		Type:         o.objectType,
+		Size:         len(o.data),
+		StorageClass: "Standard",
+	}
+	if owner {
+		content.Owner = Owner
+	}
+	return content
+}
+
+// encodeKey encode the key of listing if encoding-type is url
+func encodeKey(r *request, key string) string {
+	if r.query.Get("encoding-type") == "url" {
+		return url.QueryEscape(key)
+	}
+	return key
+}
+
+// listObjects serve ListBucket
+func (s *Server) listObjects(w http.ResponseWriter, r *request, b *bucket) error {
+	var prefix, delimiter, marker = r.query.Get("prefix"), r.query.Get("delimiter"), r.query.Get("marker")
+	var maxKeys = getMaxKeys(r.query.Get("max-keys"))
+	var entries, truncated = b.listKeys(prefix, delimiter, marker, maxKeys)
+
+	var result = oss.ListBucketResult{
+		Name:         b.name,
+		Prefix:       encodeKey(r, prefix),
+		Marker:       encodeKey(r, marker),
+		MaxKeys:      strconv.Itoa(maxKeys),
+		Delimiter:    encodeKey(r, delimiter),
+		IsTruncated:  truncated,
+		EncodingType: r.query.Get("encoding-type"),
+	}
+	for _, entry := range entries {
+		if entry.object == nil {
+			result.CommonPrefixes = append(result.CommonPrefixes, encodeKey(r, entry.key))
+		} else {
+			result.Contents = append(result.Contents, entry.object.content(encodeKey(r, entry.key), true))
+		}
+	}
+	if truncated {
+		result.NextMarker = encodeKey(r, entries[len(entries)-1].key)
+	}
+	return writeXML(w, result)
+}
+
+// listObjectsV2 serve ListBucketV2, the continuation token is the last key of previous page
+func (s *Server) listObjectsV2(w http.ResponseWriter, r *request, b *bucket) error {
+	var prefix, delimiter = r.query.Get("prefix"), r.query.Get("delimiter")
+	var marker = r.query.Get("start-after")
+	var token = r.query.Get("continuation-token")
+	if token != "" {
+		var data, err = url.QueryUnescape(token)
+		if err != nil {
+			return newError(http.StatusBadRequest, "InvalidArgument", "The continuation token is not valid.")
+		}
+		marker = data
+	}
+	var maxKeys = getMaxKeys(r.query.Get("max-keys"))
+	var entries, truncated = b.listKeys(prefix, delimiter, marker, maxKeys)
+
+	var result = oss.ListBucketV2Result{
+		Name:              b.name,
+		Prefix:            encodeKey(r, prefix),
+		StartAfter:        encodeKey(r, r.query.Get("start-after")),
+		ContinuationToken: token,
+		MaxKeys:           strconv.Itoa(maxKeys),
+		Delimiter:         encodeKey(r, delimiter),
+		IsTruncated:       truncated,
+		KeyCount:          len(entries),
+		EncodingType:      r.query.Get("encoding-type"),
+	}
+	for _, entry := range entries {
+		if entry.object == nil {
+			result.CommonPrefixes = append(result.CommonPrefixes, encodeKey(r, entry.key))
+		} else {
+			var owner = r.query.Get("fetch-owner") == "true"
+			result.Contents = append(result.Contents, entry.object.content(encodeKey(r, entry.key), owner))
+		}
+	}
+	if truncated {
+		result.NextContinuationToken = url.QueryEscape(entries[len(entries)-1].key)
+	}
+	return writeXML(w, result)
+}
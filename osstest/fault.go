@@ -0,0 +1,169 @@
+package osstest
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"time"
+)
+
+// The errors returned by OSS when it is unavailable, they are used as Fault.Error
+var (
+	ErrInternalError        = newError(http.StatusInternalServerError, "InternalError", "We encountered an internal error. Please try again.")
+	ErrServiceUnavailable   = newError(http.StatusServiceUnavailable, "ServiceUnavailable", "The service is unavailable, please try again later.")
+	ErrSlowDown             = newError(http.StatusServiceUnavailable, "SlowDown", "Please reduce your request rate.")
+	ErrRequestTimeTooSkewed = newError(http.StatusForbidden, "RequestTimeTooSkewed",
+		"The difference between the request time and the current time is too large.")
+)
+
+// Fault defined a failure injected into the matched requests.
+//
+// The request is matched by Method, Path, Param and Attempts, the zero value matches all.
+// The attempts are counted by the method and url of request, so the retries of a request
+// are the attempts 2, 3 and so on.
+type Fault struct {
+	// the method of request, e.g.: PUT
+	Method string
+	// the pattern of path matched by path.Match, e.g.: /bucket/*
+	Path string
+	// the query param the request has, e.g.: partNumber, uploads
+	Param string
+	// the attempts of request to fail, starting from 1, empty means all the attempts
+	Attempts []int
+
+	// delay the response, it is applied before the other faults
+	Latency time.Duration
+	// response the error instead of serving the request, e.g.: ErrSlowDown
+	Error *Error
+	// abort the connection with a TCP reset after BodyBytes bytes of response body are sent
+	Reset bool
+	// close the connection after BodyBytes bytes of response body are sent,
+	// the Content-Length is not changed, so the client get a truncated response
+	Truncate bool
+	// the bytes of response body sent before Reset or Truncate,
+	// negative means the response headers are not sent either.
+	// The request is served before Reset or Truncate, the changes of it are kept.
+	BodyBytes int
+}
+
+// match check the fault is applied to the attempt of request
+func (f *Fault) match(req *http.Request, attempt int) bool {
+	if f.Method != "" && f.Method != req.Method {
+		return false
+	}
+	if f.Path != "" {
+		if ok, _ := path.Match(f.Path, req.URL.Path); !ok {
+			return false
+		}
+	}
+	if f.Param != "" {
+		if _, ok := req.URL.Query()[f.Param]; !ok {
+			return false
+		}
+	}
+	if len(f.Attempts) == 0 {
+		return true
+	}
+	for _, a := range f.Attempts {
+		if a == attempt {
+			return true
+		}
+	}
+	return false
+}
+
+// AddFault add a fault into server, the first matched fault is applied to a request
+func (s *Server) AddFault(fault *Fault) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	s.faults = append(s.faults, fault)
+}
+
+// ClearFaults remove all the faults and reset the attempts of requests
+func (s *Server) ClearFaults() {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	s.faults = nil
+	s.attempts = nil
+}
+
+// getFault count the attempt of request and get the matched fault, nil means no fault
+func (s *Server) getFault(req *http.Request) *Fault {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if len(s.faults) == 0 {
+		return nil
+	}
+	if s.attempts == nil {
+		s.attempts = make(map[string]int)
+	}
+	var key = req.Method + " " + req.URL.RequestURI()
+	s.attempts[key]++
+	for _, fault := range s.faults {
+		if fault.match(req, s.attempts[key]) {
+			return fault
+		}
+	}
+	return nil
+}
+
+// serveFault serve the request with the fault
+func (s *Server) serveFault(w http.ResponseWriter, req *http.Request, fault *Fault, requestID string) {
+	if fault.Latency > 0 {
+		var timer = time.NewTimer(fault.Latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return
+		}
+	}
+	if fault.Error != nil {
+		writeError(w, req, fault.Error, requestID)
+		return
+	}
+	if !fault.Reset && !fault.Truncate {
+		s.serveRequest(w, req, requestID)
+		return
+	}
+
+	var rec = httptest.NewRecorder()
+	s.serveRequest(rec, req, requestID)
+	if fault.BodyBytes >= 0 {
+		var body = rec.Body.Bytes()
+		var n = fault.BodyBytes
+		if n > len(body) {
+			n = len(body)
+		}
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		if w.Header().Get("Content-Length") == "" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(body[:n])
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+	abortConn(w, fault.Reset)
+}
+
+// abortConn close the connection of response, reset means a TCP reset instead of a normal close
+func abortConn(w http.ResponseWriter, reset bool) {
+	var hijacker, ok = w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+	var conn, _, err = hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok && reset {
+		tcpConn.SetLinger(0)
+	}
+	conn.Close()
+}
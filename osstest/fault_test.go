@@ -0,0 +1,190 @@
+package osstest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lupino/oss-go-sdk"
+)
+
+func newFaultTestAPI(t *testing.T) (*Server, *oss.API) {
+	var server, api = newTestAPI(t)
+	var policy = oss.GetDefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	api.SetRetryPolicy(policy)
+	return server, api
+}
+
+func TestFaultError(t *testing.T) {
+	var server, api = newFaultTestAPI(t)
+	defer server.Close()
+
+	server.AddFault(&Fault{Method: "PUT", Path: "/bucket/*", Attempts: []int{1, 2}, Error: ErrSlowDown})
+	if err := api.PutObject("bucket", "object", strings.NewReader("data"), nil); err != nil {
+		t.Fatalf("PutObject: except: nil, but got: %s\n", err)
+	}
+	if data, _ := server.Object("bucket", "object"); string(data) != "data" {
+		t.Fatalf("PutObject: except: %s, but got: %s\n", "data", data)
+	}
+
+	server.ClearFaults()
+	server.AddFault(&Fault{Method: "GET", Error: ErrRequestTimeTooSkewed})
+	if _, err := api.GetObject("bucket", "object", nil, nil); getErrorCode(err) != "RequestTimeTooSkewed" {
+		t.Fatalf("GetObject: except: RequestTimeTooSkewed, but got: %v\n", err)
+	}
+
+	server.ClearFaults()
+	server.AddFault(&Fault{Error: ErrInternalError})
+	if _, err := api.HeadObject("bucket", "object", nil); err == nil {
+		t.Fatalf("HeadObject: except: InternalError, but got: nil\n")
+	}
+	if err := api.DeleteObject("bucket", "object"); getErrorCode(err) != "InternalError" {
+		t.Fatalf("DeleteObject: except: InternalError, but got: %v\n", err)
+	}
+}
+
+func TestFaultReset(t *testing.T) {
+	var server, api = newFaultTestAPI(t)
+	defer server.Close()
+
+	server.AddFault(&Fault{Method: "PUT", Attempts: []int{1}, Reset: true, BodyBytes: -1})
+	if err := api.PutObject("bucket", "object", strings.NewReader("hello world"), nil); err != nil {
+		t.Fatalf("PutObject: except: nil, but got: %s\n", err)
+	}
+
+	server.ClearFaults()
+	server.AddFault(&Fault{Method: "GET", Path: "/bucket/object", Reset: true, BodyBytes: 5})
+	var reader, err = api.GetObject("bucket", "object", nil, nil)
+	if err != nil {
+		t.Fatalf("GetObject: except: nil, but got: %s\n", err)
+	}
+	var data, _ = ioutil.ReadAll(reader)
+	reader.Close()
+	if len(data) >= 11 {
+		t.Fatalf("GetObject: except: a partial body, but got: %s\n", data)
+	}
+}
+
+func TestFaultTruncate(t *testing.T) {
+	var server, api = newFaultTestAPI(t)
+	defer server.Close()
+
+	api.PutObject("bucket", "object", strings.NewReader("hello world"), nil)
+	server.AddFault(&Fault{Method: "GET", Truncate: true, BodyBytes: 5})
+	var reader, err = api.GetObject("bucket", "object", nil, nil)
+	if err != nil {
+		t.Fatalf("GetObject: except: nil, but got: %s\n", err)
+	}
+	var data []byte
+	data, err = ioutil.ReadAll(reader)
+	reader.Close()
+	if err != io.ErrUnexpectedEOF || string(data) != "hello" {
+		t.Fatalf("GetObject: except: %s %s, but got: %s %v\n", "hello", io.ErrUnexpectedEOF, data, err)
+	}
+}
+
+func TestFaultLatency(t *testing.T) {
+	var server, api = newFaultTestAPI(t)
+	defer server.Close()
+
+	api.PutObject("bucket", "object", strings.NewReader("data"), nil)
+	server.AddFault(&Fault{Method: "GET", Latency: 10 * time.Second})
+	var ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var start = time.Now()
+	if _, err := api.GetObjectWithContext(ctx, "bucket", "object", nil, nil); err == nil {
+		t.Fatalf("GetObject: except: context deadline exceeded, but got: nil\n")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("GetObject: except: canceled by context, but got: %s\n", time.Since(start))
+	}
+
+	server.ClearFaults()
+	server.AddFault(&Fault{Method: "GET", Latency: 10 * time.Millisecond})
+	if reader, err := api.GetObject("bucket", "object", nil, nil); err != nil {
+		t.Fatalf("GetObject: except: nil, but got: %s\n", err)
+	} else {
+		reader.Close()
+	}
+}
+
+func TestFaultResume(t *testing.T) {
+	var server, api = newFaultTestAPI(t)
+	defer server.Close()
+
+	var dir, err = ioutil.TempDir("", "osstest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var data = bytes.Repeat([]byte("0123456789"), oss.MinPartSize/4)
+	var fileName = filepath.Join(dir, "file")
+	ioutil.WriteFile(fileName, data, 0600)
+
+	// the first attempt of every part fails
+	server.AddFault(&Fault{Method: "PUT", Param: "partNumber", Attempts: []int{1}, Error: ErrInternalError})
+	var uploadOpts = oss.GetDefaultUploadOptions()
+	uploadOpts.PartSize = oss.MinPartSize
+	if _, err = api.UploadFile("bucket", "large", fileName, uploadOpts); err != nil {
+		t.Fatalf("UploadFile: except: nil, but got: %s\n", err)
+	}
+
+	// the second range is truncated, the download is resumed by the checkpoint
+	server.ClearFaults()
+	server.AddFault(&Fault{Method: "GET", Path: "/bucket/large", Attempts: []int{2}, Truncate: true, BodyBytes: 10})
+	var downloadOpts = oss.GetDefaultDownloadOptions()
+	downloadOpts.PartSize = oss.MinPartSize
+	downloadOpts.Concurrency = 1
+	downloadOpts.Checkpoint = filepath.Join(dir, "download.cp")
+	var downloadName = filepath.Join(dir, "download")
+	if err = api.DownloadFile("bucket", "large", downloadName, downloadOpts); err == nil {
+		t.Fatalf("DownloadFile: except: unexpected EOF, but got: nil\n")
+	}
+	if _, err = os.Stat(downloadOpts.Checkpoint); err != nil {
+		t.Fatalf("DownloadFile: except: the checkpoint is kept, but got: %s\n", err)
+	}
+	if err = api.DownloadFile("bucket", "large", downloadName, downloadOpts); err != nil {
+		t.Fatalf("DownloadFile: except: nil, but got: %s\n", err)
+	}
+	if downloaded, _ := ioutil.ReadFile(downloadName); !bytes.Equal(downloaded, data) {
+		t.Fatalf("DownloadFile: the file is not equal to the object\n")
+	}
+}
+
+func TestFaultCompleteUpload(t *testing.T) {
+	var server, api = newFaultTestAPI(t)
+	defer server.Close()
+
+	// the complete POST fails after the retries, the upload and its parts are aborted
+	server.AddFault(&Fault{Method: "POST", Param: "uploadId", Error: ErrInternalError})
+	var data = bytes.Repeat([]byte("0123456789"), oss.MinPartSize/4)
+	var uploadOpts = oss.GetDefaultUploadOptions()
+	uploadOpts.PartSize = oss.MinPartSize
+	var _, err = api.Upload("bucket", "large", bytes.NewReader(data), int64(len(data)), uploadOpts)
+	if getErrorCode(err) != "InternalError" {
+		t.Fatalf("Upload: except: InternalError, but got: %v\n", err)
+	}
+	var uploads = api.ListUploads("bucket", nil)
+	if uploads.Next() || uploads.Err() != nil {
+		t.Fatalf("Upload: except: the upload is aborted, but got: %+v %v\n", uploads.Upload(), uploads.Err())
+	}
+	if _, ok := server.Object("bucket", "large"); ok {
+		t.Fatalf("Upload: except: the object is not created, but got: it exists\n")
+	}
+
+	var w = api.NewObjectWriter("bucket", "large", uploadOpts)
+	w.Write(data)
+	if err = w.Close(); getErrorCode(err) != "InternalError" {
+		t.Fatalf("ObjectWriter.Close: except: InternalError, but got: %v\n", err)
+	}
+	if uploads = api.ListUploads("bucket", nil); uploads.Next() || uploads.Err() != nil {
+		t.Fatalf("ObjectWriter.Close: except: the upload is aborted, but got: %+v %v\n", uploads.Upload(), uploads.Err())
+	}
+}
@@ -0,0 +1,679 @@
+package osstest
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"hash/crc64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Lupino/oss-go-sdk"
+)
+
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// object defined the state of an object
+type object struct {
+	data       []byte
+	etag       string
+	modified   time.Time
+	objectType string
+	acl        string
+	// the headers of object, e.g.: Content-Type, x-oss-meta-*
+	header http.Header
+}
+
+// upload defined the state of a multipart upload
+type upload struct {
+	id        string
+	key       string
+	initiated time.Time
+	header    http.Header
+	parts     map[int]*object
+}
+
+// storedHeaders defined the headers of request stored with object
+var storedHeaders = []string{"Content-Type", "Content-Encoding", "Content-Disposition", "Content-Language",
+	"Cache-Control", "Expires"}
+
+// getObjectHeader get the headers of request stored with object
+func getObjectHeader(header http.Header) http.Header {
+	var result = make(http.Header)
+	for _, k := range storedHeaders {
+		if v := header.Get(k); v != "" {
+			result.Set(k, v)
+		}
+	}
+	for k, v := range header {
+		if strings.HasPrefix(strings.ToLower(k), oss.UserMetaPrefix) {
+			result[k] = v
+		}
+	}
+	if result.Get("Content-Type") == "" {
+		result.Set("Content-Type", "application/octet-stream")
+	}
+	return result
+}
+
+func md5Sum(data []byte) []byte {
+	var sum = md5.Sum(data)
+	return sum[:]
+}
+
+// newObject create an object of data
+func newObject(data []byte, objectType string, header http.Header) *object {
+	return &object{
+		data:       data,
+		etag:       fmt.Sprintf("\"%s\"", strings.ToUpper(hex.EncodeToString(md5Sum(data)))),
+		modified:   time.Now().UTC(),
+		objectType: objectType,
+		acl:        "default",
+		header:     header,
+	}
+}
+
+// crc64 get the CRC64 of object data
+func (o *object) crc64() string {
+	return strconv.FormatUint(crc64.Checksum(o.data, crc64Table), 10)
+}
+
+// writeHeader write the meta of object into the response headers
+func (o *object) writeHeader(w http.ResponseWriter) {
+	for k, v := range o.header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("ETag", o.etag)
+	w.Header().Set("Last-Modified", o.modified.Format(http.TimeFormat))
+	w.Header().Set("x-oss-object-type", o.objectType)
+	w.Header().Set("x-oss-storage-class", "Standard")
+	w.Header().Set("x-oss-hash-crc64ecma", o.crc64())
+	if o.objectType == "Appendable" {
+		w.Header().Set("x-oss-next-append-position", strconv.Itoa(len(o.data)))
+	}
+}
+
+// serveObject serve the operations of object
+func (s *Server) serveObject(w http.ResponseWriter, r *request) error {
+	if r.Method == "OPTIONS" {
+		return s.optionObject(w, r)
+	}
+	var b = s.buckets[r.bucket]
+	if b == nil {
+		return errNoSuchBucket
+	}
+	var o = b.objects[r.object]
+	var acl = b.acl
+	if o != nil && o.acl != "default" {
+		acl = o.acl
+	}
+	if !canAccess(r, acl) || (r.has("acl") && !r.signed) {
+		return errAccessDenied
+	}
+
+	switch {
+	case r.has("acl"):
+		return s.serveObjectACL(w, r, o)
+	case r.has("uploads"):
+		if r.Method != "POST" {
+			return errMethodNotAllowed
+		}
+		return s.initiateUpload(w, r, b)
+	case r.has("uploadId"):
+		return s.serveUpload(w, r, b)
+	case r.has("append"):
+		if r.Method != "POST" {
+			return errMethodNotAllowed
+		}
+		return s.appendObject(w, r, b)
+	}
+
+	switch r.Method {
+	case "GET", "HEAD":
+		if o == nil {
+			return errNoSuchKey
+		}
+		return s.getObject(w, r, o)
+	case "PUT":
+		if r.Header.Get("x-oss-copy-source") != "" {
+			return s.copyObject(w, r, b)
+		}
+		return s.putObject(w, r, b)
+	case "DELETE":
+		delete(b.objects, r.object)
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	case "POST":
+		return errNotImplemented
+	}
+	return errMethodNotAllowed
+}
+
+// putObject serve PutObject
+func (s *Server) putObject(w http.ResponseWriter, r *request, b *bucket) error {
+	if err := checkContentMD5(r); err != nil {
+		return err
+	}
+	if r.Header.Get("x-oss-forbid-overwrite") == "true" && b.objects[r.object] != nil {
+		return newError(http.StatusConflict, "FileAlreadyExists", "The object you specified already exists and can not be overwritten.")
+	}
+	var o = newObject(r.body, "Normal", getObjectHeader(r.Header))
+	if acl := r.Header.Get("x-oss-object-acl"); isValidACL(acl) {
+		o.acl = acl
+	}
+	b.objects[r.object] = o
+	w.Header().Set("ETag", o.etag)
+	w.Header().Set("x-oss-hash-crc64ecma", o.crc64())
+	return nil
+}
+
+// checkConditions check the If-* headers of request
+func checkConditions(r *request, o *object) error {
+	if v := r.Header.Get("If-Match"); v != "" && v != o.etag {
+		return newError(http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold.")
+	}
+	if v := r.Header.Get("If-Unmodified-Since"); v != "" {
+		if t, err := http.ParseTime(v); err == nil && o.modified.Truncate(time.Second).After(t) {
+			return newError(http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold.")
+		}
+	}
+	if v := r.Header.Get("If-None-Match"); v != "" && v == o.etag {
+		return &Error{StatusCode: http.StatusNotModified}
+	}
+	if v := r.Header.Get("If-Modified-Since"); v != "" {
+		if t, err := http.ParseTime(v); err == nil && !o.modified.Truncate(time.Second).After(t) {
+			return &Error{StatusCode: http.StatusNotModified}
+		}
+	}
+	return nil
+}
+
+// parseRange parse the single range "bytes=start-end" of size, ok is false if the range is ignored
+func parseRange(value string, size int64) (start, end int64, ok bool) {
+	if !strings.HasPrefix(value, "bytes=") || strings.Contains(value, ",") {
+		return 0, 0, false
+	}
+	var parts = strings.SplitN(strings.TrimPrefix(value, "bytes="), "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	var err error
+	if parts[0] == "" {
+		// the last n bytes
+		var n int64
+		if n, err = strconv.ParseInt(parts[1], 10, 64); err != nil || n <= 0 {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, size > 0
+	}
+	if start, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return 0, 0, false
+	}
+	end = size - 1
+	if parts[1] != "" {
+		if end, err = strconv.ParseInt(parts[1], 10, 64); err != nil || end < start {
+			return 0, 0, false
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+	// the invalid range is ignored like OSS does
+	return start, end, start < size
+}
+
+// getObject serve GetObject and HeadObject, the single Range is supported
+func (s *Server) getObject(w http.ResponseWriter, r *request, o *object) error {
+	if err := checkConditions(r, o); err != nil {
+		if e := err.(*Error); e.StatusCode == http.StatusNotModified {
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+		return err
+	}
+	o.writeHeader(w)
+	var overrides = map[string]string{
+		"response-content-type":        "Content-Type",
+		"response-content-language":    "Content-Language",
+		"response-expires":             "Expires",
+		"response-cache-control":       "Cache-Control",
+		"response-content-disposition": "Content-Disposition",
+		"response-content-encoding":    "Content-Encoding",
+	}
+	for param, header := range overrides {
+		if v := r.query.Get(param); v != "" {
+			w.Header().Set(header, v)
+		}
+	}
+	w.Header().Set("Accept-Ranges", "bytes")
+
+	var size = int64(len(o.data))
+	var data = o.data
+	var status = http.StatusOK
+	if start, end, ok := parseRange(r.Header.Get("Range"), size); ok {
+		data = o.data[start : end+1]
+		status = http.StatusPartialContent
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method == "GET" {
+		w.Write(data)
+	}
+	return nil
+}
+
+// getCopySource get the source object of x-oss-copy-source, e.g.: /bucket/object
+func (s *Server) getCopySource(r *request) (*object, error) {
+	var source = strings.TrimPrefix(r.Header.Get("x-oss-copy-source"), "/")
+	var parts = strings.SplitN(source, "/", 2)
+	if len(parts) != 2 {
+		return nil, newError(http.StatusBadRequest, "InvalidArgument", "Copy Source must mention the source bucket and key.")
+	}
+	var object, err = url.QueryUnescape(parts[1])
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, "InvalidArgument", "The copy source is not valid.")
+	}
+	var b = s.buckets[parts[0]]
+	if b == nil {
+		return nil, errNoSuchBucket
+	}
+	var o = b.objects[object]
+	if o == nil {
+		return nil, errNoSuchKey
+	}
+	var acl = b.acl
+	if o.acl != "default" {
+		acl = o.acl
+	}
+	if !r.signed && acl != string(oss.ACLPublicRead) && acl != string(oss.ACLPublicReadWrite) {
+		return nil, errAccessDenied
+	}
+	return o, nil
+}
+
+// copyObject serve CopyObject, the meta is copied unless x-oss-metadata-directive is REPLACE
+func (s *Server) copyObject(w http.ResponseWriter, r *request, b *bucket) error {
+	var source, err = s.getCopySource(r)
+	if err != nil {
+		return err
+	}
+	if v := r.Header.Get("x-oss-copy-source-if-match"); v != "" && v != source.etag {
+		return newError(http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold.")
+	}
+	var header = source.header
+	if r.Header.Get("x-oss-metadata-directive") == "REPLACE" {
+		header = getObjectHeader(r.Header)
+	}
+	var o = newObject(append([]byte(nil), source.data...), "Normal", header)
+	if source.objectType == "Multipart" {
+		// the ETag of multipart object is kept
+		o.etag = source.etag
+		o.objectType = source.objectType
+	}
+	b.objects[r.object] = o
+	return writeXML(w, oss.CopyObjectResult{
+		LastModified: o.modified,
+		ETag:         o.etag,
+	})
+}
+
+// appendObject serve AppendObject
+func (s *Server) appendObject(w http.ResponseWriter, r *request, b *bucket) error {
+	if err := checkContentMD5(r); err != nil {
+		return err
+	}
+	var position, err = strconv.Atoi(r.query.Get("position"))
+	if err != nil || position < 0 {
+		return newError(http.StatusBadRequest, "InvalidArgument", "The position of append is not valid.")
+	}
+	var o = b.objects[r.object]
+	if o != nil && o.objectType != "Appendable" {
+		return newError(http.StatusConflict, "ObjectNotAppendable", "The object is not appendable.")
+	}
+	var length int
+	if o != nil {
+		length = len(o.data)
+	}
+	if position != length {
+		var e = newError(http.StatusConflict, "PositionNotEqualToLength", "Position is not equal to file length.")
+		e.Header = map[string]string{"x-oss-next-append-position": strconv.Itoa(length)}
+		return e
+	}
+	var updated *object
+	if o == nil {
+		updated = newObject(r.body, "Appendable", getObjectHeader(r.Header))
+	} else {
+		updated = newObject(append(append([]byte(nil), o.data...), r.body...), "Appendable", o.header)
+		updated.acl = o.acl
+	}
+	b.objects[r.object] = updated
+	w.Header().Set("ETag", updated.etag)
+	w.Header().Set("x-oss-next-append-position", strconv.Itoa(len(updated.data)))
+	w.Header().Set("x-oss-hash-crc64ecma", updated.crc64())
+	return nil
+}
+
+// serveObjectACL get or put the ACL of object
+func (s *Server) serveObjectACL(w http.ResponseWriter, r *request, o *object) error {
+	if o == nil {
+		return errNoSuchKey
+	}
+	switch r.Method {
+	case "GET":
+		return writeACL(w, o.acl)
+	case "PUT":
+		var acl = r.Header.Get("x-oss-object-acl")
+		if !isValidACL(acl) && acl != "default" {
+			return newError(http.StatusBadRequest, "InvalidArgument", "no such object access control exists")
+		}
+		o.acl = acl
+		return nil
+	}
+	return errMethodNotAllowed
+}
+
+// deleteObjects serve DeleteObjects
+func (s *Server) deleteObjects(w http.ResponseWriter, r *request, b *bucket) error {
+	if err := checkContentMD5(r); err != nil {
+		return err
+	}
+	var deleteXML struct {
+		Quiet   bool
+		Objects []struct {
+			Key string
+		} `xml:"Object"`
+	}
+	if err := decodeXML(r, &deleteXML); err != nil {
+		return err
+	}
+	if len(deleteXML.Objects) > oss.MaxDeleteObjects {
+		return errMalformedXML
+	}
+	var result oss.DeleteResult
+	for _, object := range deleteXML.Objects {
+		delete(b.objects, object.Key)
+		if !deleteXML.Quiet {
+			result.Objects = append(result.Objects, object.Key)
+		}
+	}
+	return writeXML(w, result)
+}
+
+// matchPattern match the value with the pattern which may contain one "*"
+func matchPattern(pattern, value string) bool {
+	var i = strings.Index(pattern, "*")
+	if i < 0 {
+		return pattern == value
+	}
+	return len(value) >= len(pattern)-1 && strings.HasPrefix(value, pattern[:i]) && strings.HasSuffix(value, pattern[i+1:])
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(strings.ToLower(pattern), strings.ToLower(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// optionObject serve the CORS preflight request by the CORS rules of bucket
+func (s *Server) optionObject(w http.ResponseWriter, r *request) error {
+	var b = s.buckets[r.bucket]
+	if b == nil {
+		return errNoSuchBucket
+	}
+	var forbidden = newError(http.StatusForbidden, "AccessForbidden", "CORSResponse: This CORS request is not allowed.")
+	var origin = r.Header.Get("Origin")
+	var method = r.Header.Get("Access-Control-Request-Method")
+	if origin == "" || method == "" || b.configs["cors"] == nil {
+		return forbidden
+	}
+	var config oss.CORSConfiguration
+	if err := decodeXML(&request{body: b.configs["cors"]}, &config); err != nil {
+		return err
+	}
+	var headers []string
+	for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, header)
+		}
+	}
+	for _, rule := range config.Rules {
+		if !matchAny(rule.AllowedOrigin, origin) || !matchAny(rule.AllowedMethod, method) {
+			continue
+		}
+		var allowed = true
+		for _, header := range headers {
+			allowed = allowed && matchAny(rule.AllowedHeader, header)
+		}
+		if !allowed {
+			continue
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(rule.AllowedMethod, ", "))
+		if len(headers) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+		}
+		if len(rule.ExposeHeader) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(rule.ExposeHeader, ", "))
+		}
+		if rule.MaxAgeSeconds > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(rule.MaxAgeSeconds))
+		}
+		return nil
+	}
+	return forbidden
+}
+
+// initiateUpload serve NewMultipartUpload
+func (s *Server) initiateUpload(w http.ResponseWriter, r *request, b *bucket) error {
+	s.uploadID++
+	var u = &upload{
+		id:        fmt.Sprintf("%032X", s.uploadID),
+		key:       r.object,
+		initiated: time.Now().UTC(),
+		header:    getObjectHeader(r.Header),
+		parts:     make(map[int]*object),
+	}
+	b.uploads[u.id] = u
+	return writeXML(w, oss.InitiateMultipartUploadResult{
+		Bucket:   b.name,
+		Key:      u.key,
+		UploadID: u.id,
+	})
+}
+
+// serveUpload serve the operations of multipart upload
+func (s *Server) serveUpload(w http.ResponseWriter, r *request, b *bucket) error {
+	var u = b.uploads[r.query.Get("uploadId")]
+	if u == nil || u.key != r.object {
+		return errNoSuchUpload
+	}
+	switch r.Method {
+	case "PUT":
+		return s.uploadPart(w, r, b, u)
+	case "POST":
+		return s.completeUpload(w, r, b, u)
+	case "GET":
+		return s.listParts(w, r, b, u)
+	case "DELETE":
+		delete(b.uploads, u.id)
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	return errMethodNotAllowed
+}
+
+// uploadPart serve UploadPart and CopyPart
+func (s *Server) uploadPart(w http.ResponseWriter, r *request, b *bucket, u *upload) error {
+	var partNumber, err = strconv.Atoi(r.query.Get("partNumber"))
+	if err != nil || partNumber < 1 || partNumber > oss.MaxPartCount {
+		return newError(http.StatusBadRequest, "InvalidArgument", "Part number must be an integer between 1 and 10000, inclusive.")
+	}
+	if r.Header.Get("x-oss-copy-source") == "" {
+		if err = checkContentMD5(r); err != nil {
+			return err
+		}
+		var part = newObject(r.body, "Normal", nil)
+		u.parts[partNumber] = part
+		w.Header().Set("ETag", part.etag)
+		w.Header().Set("x-oss-hash-crc64ecma", part.crc64())
+		return nil
+	}
+
+	var source *object
+	if source, err = s.getCopySource(r); err != nil {
+		return err
+	}
+	var data = source.data
+	if v := r.Header.Get("x-oss-copy-source-range"); v != "" {
+		var start, end, ok = parseRange(v, int64(len(data)))
+		if !ok {
+			return newError(http.StatusBadRequest, "InvalidArgument", "The copy source range is not valid.")
+		}
+		data = data[start : end+1]
+	}
+	var part = newObject(append([]byte(nil), data...), "Normal", nil)
+	u.parts[partNumber] = part
+	return writeXML(w, oss.CopyPartResult{
+		LastModified: part.modified.Format(time.RFC3339),
+		ETag:         part.etag,
+	})
+}
+
+// completeUpload serve CompleteUpload, the parts must be ascending and all but the last are at least 100KB
+func (s *Server) completeUpload(w http.ResponseWriter, r *request, b *bucket, u *upload) error {
+	var complete struct {
+		Parts []struct {
+			PartNumber int
+			ETag       string
+		} `xml:"Part"`
+	}
+	if err := decodeXML(r, &complete); err != nil {
+		return err
+	}
+	if len(complete.Parts) == 0 {
+		return errMalformedXML
+	}
+	var data []byte
+	var md5s []byte
+	for i, p := range complete.Parts {
+		if i > 0 && p.PartNumber <= complete.Parts[i-1].PartNumber {
+			return newError(http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order.")
+		}
+		var part = u.parts[p.PartNumber]
+		if part == nil || strings.Trim(p.ETag, "\"") != strings.Trim(part.etag, "\"") {
+			return newError(http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found.")
+		}
+		if i < len(complete.Parts)-1 && len(part.data) < oss.MinPartSize {
+			return newError(http.StatusBadRequest, "EntityTooSmall", "Your proposed upload is smaller than the minimum allowed size.")
+		}
+		data = append(data, part.data...)
+		md5s = append(md5s, md5Sum(part.data)...)
+	}
+	var o = newObject(data, "Multipart", u.header)
+	o.etag = fmt.Sprintf("\"%s-%d\"", strings.ToUpper(hex.EncodeToString(md5Sum(md5s))), len(complete.Parts))
+	b.objects[u.key] = o
+	delete(b.uploads, u.id)
+	w.Header().Set("x-oss-hash-crc64ecma", o.crc64())
+	return writeXML(w, oss.CompleteMultipartUploadResult{
+		Location: fmt.Sprintf("http://%s/%s/%s", r.Host, b.name, url.QueryEscape(u.key)),
+		Bucket:   b.name,
+		Key:      u.key,
+		ETag:     o.etag,
+	})
+}
+
+// listParts serve ListParts
+func (s *Server) listParts(w http.ResponseWriter, r *request, b *bucket, u *upload) error {
+	var marker, _ = strconv.Atoi(r.query.Get("part-number-marker"))
+	var maxParts, err = strconv.Atoi(r.query.Get("max-parts"))
+	if err != nil || maxParts <= 0 || maxParts > 1000 {
+		maxParts = 1000
+	}
+	var numbers []int
+	for n := range u.parts {
+		if n > marker {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+
+	var result = oss.ListPartsResult{
+		Bucket:   b.name,
+		Key:      u.key,
+		UploadID: u.id,
+		MaxParts: maxParts,
+	}
+	if len(numbers) > maxParts {
+		numbers = numbers[:maxParts]
+		result.IsTruncated = true
+		result.NextPartNumberMarker = numbers[maxParts-1]
+	}
+	for _, n := range numbers {
+		var part = u.parts[n]
+		result.Parts = append(result.Parts, oss.Part{
+			PartNumber:   n,
+			ETag:         part.etag,
+			LastModified: part.modified,
+			Size:         len(part.data),
+		})
+	}
+	return writeXML(w, result)
+}
+
+// listUploads serve ListMultipartUpload, the uploads are sorted by key and upload id
+func (s *Server) listUploads(w http.ResponseWriter, r *request, b *bucket) error {
+	var prefix = r.query.Get("prefix")
+	var keyMarker, uploadIDMarker = r.query.Get("key-marker"), r.query.Get("upload-id-marker")
+	var maxUploads, err = strconv.Atoi(r.query.Get("max-uploads"))
+	if err != nil || maxUploads <= 0 || maxUploads > 1000 {
+		maxUploads = 1000
+	}
+	var uploads []*upload
+	for _, u := range b.uploads {
+		if !strings.HasPrefix(u.key, prefix) {
+			continue
+		}
+		if u.key > keyMarker || (u.key == keyMarker && uploadIDMarker != "" && u.id > uploadIDMarker) {
+			uploads = append(uploads, u)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		if uploads[i].key != uploads[j].key {
+			return uploads[i].key < uploads[j].key
+		}
+		return uploads[i].id < uploads[j].id
+	})
+
+	var result = oss.ListMultipartUploadsResult{
+		Bucket:         b.name,
+		KeyMarker:      keyMarker,
+		UploadIDMarker: uploadIDMarker,
+		Prefix:         prefix,
+		MaxUploads:     strconv.Itoa(maxUploads),
+	}
+	if len(uploads) > maxUploads {
+		uploads = uploads[:maxUploads]
+		result.IsTruncated = true
+		result.NextKeyMarker = uploads[maxUploads-1].key
+		result.NextUploadIDMarker = uploads[maxUploads-1].id
+	}
+	for _, u := range uploads {
+		result.Uploads = append(result.Uploads, oss.Upload{
+			Key:       u.key,
+			UploadID:  u.id,
+			Initiated: u.initiated,
+		})
+	}
+	return writeXML(w, result)
+}
@@ -0,0 +1,360 @@
+// Package osstest provide an in-memory OSS server for testing.
+//
+// The server keeps buckets, objects, multipart uploads and bucket configs in memory,
+// and checks the V1 signature of each request like OSS does, so the code using oss.API
+// can be tested without network:
+//
+//	var server = osstest.NewServer(nil)
+//	defer server.Close()
+//	var api, _ = server.NewAPI()
+//	api.PutBucket("bucket", oss.ACLPrivate, "", nil)
+//
+// The buckets are addressed by path, e.g.: http://127.0.0.1:port/bucket/object,
+// it is what oss.API does for an IP host.
+//
+// The failures of OSS, e.g.: 503 SlowDown, latency and connection resets, can be injected
+// by AddFault to test the retry and resume logic:
+//
+//	server.AddFault(&osstest.Fault{Method: "PUT", Attempts: []int{1}, Error: osstest.ErrSlowDown})
+package osstest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Lupino/oss-go-sdk"
+)
+
+// ServerOptions defined the options of Server
+type ServerOptions struct {
+	// the credentials accepted by the server
+	AccessID        string
+	SecretAccessKey string
+	// the STS token must be sent with the AccessID if it is not empty
+	StsToken string
+	// the location of buckets created without location, default is oss-cn-hangzhou
+	Location string
+	// the max difference between the Date of request and the server time, default is 15 minutes
+	MaxClockSkew time.Duration
+}
+
+// GetDefaultServerOptions get default server options
+func GetDefaultServerOptions() *ServerOptions {
+	return &ServerOptions{
+		AccessID:        "osstest-access-id",
+		SecretAccessKey: "osstest-access-secret",
+		Location:        "oss-cn-hangzhou",
+		MaxClockSkew:    15 * time.Minute,
+	}
+}
+
+// Owner the owner of all the buckets and objects
+var Owner = oss.Owner{ID: "osstest", DisplayName: "osstest"}
+
+// Server is an in-memory OSS server, it implements http.Handler
+type Server struct {
+	// URL the base url of server, e.g.: http://127.0.0.1:port
+	URL string
+
+	opts   *ServerOptions
+	server *httptest.Server
+
+	locker    sync.Mutex
+	buckets   map[string]*bucket
+	requestID int64
+	uploadID  int64
+
+	// the injected faults and the attempts of requests
+	faults   []*Fault
+	attempts map[string]int
+}
+
+// NewServer start a server on a local port, nil opts means GetDefaultServerOptions().
+// The server must be closed by Close.
+func NewServer(opts *ServerOptions) *Server {
+	var s = NewHandler(opts)
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL
+	return s
+}
+
+// NewHandler create a server without listening, it is used as http.Handler,
+// nil opts means GetDefaultServerOptions().
+func NewHandler(opts *ServerOptions) *Server {
+	if opts == nil {
+		opts = GetDefaultServerOptions()
+	}
+	return &Server{
+		opts:    opts,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Close shut down the server
+func (s *Server) Close() {
+	if s.server != nil {
+		s.server.Close()
+	}
+}
+
+// APIOptions get the options of oss.API to access the server with the credentials of server
+func (s *Server) APIOptions() *oss.APIOptions {
+	var options = oss.GetDefaultAPIOptioins()
+	var u, _ = url.Parse(s.URL)
+	var host, port, _ = net.SplitHostPort(u.Host)
+	options.Host = host
+	options.Port, _ = strconv.Atoi(port)
+	options.AccessID = s.opts.AccessID
+	options.SecretAccessKey = s.opts.SecretAccessKey
+	options.StsToken = s.opts.StsToken
+	return options
+}
+
+// NewAPI create an oss.API to access the server
+func (s *Server) NewAPI() (*oss.API, error) {
+	return oss.NewAPI(s.APIOptions())
+}
+
+// CreateBucket create a private bucket directly, it does nothing if the bucket exists
+func (s *Server) CreateBucket(name string) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if s.buckets[name] == nil {
+		s.buckets[name] = newBucket(name, s.opts.Location, string(oss.ACLPrivate))
+	}
+}
+
+// Object get the data of object directly
+func (s *Server) Object(bucketName, objectName string) ([]byte, bool) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	var b = s.buckets[bucketName]
+	if b == nil || b.objects[objectName] == nil {
+		return nil, false
+	}
+	return append([]byte(nil), b.objects[objectName].data...), true
+}
+
+// request defined a parsed request
+type request struct {
+	*http.Request
+	bucket string
+	object string
+	query  url.Values
+	body   []byte
+	// the request is signed by the credentials of server
+	signed bool
+}
+
+// has check the query has the param, e.g.: acl, uploads
+func (r *request) has(param string) bool {
+	var _, ok = r.query[param]
+	return ok
+}
+
+// ServeHTTP serve the OSS requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.locker.Lock()
+	s.requestID++
+	var requestID = fmt.Sprintf("%024X", s.requestID)
+	s.locker.Unlock()
+	w.Header().Set("x-oss-request-id", requestID)
+	w.Header().Set("Server", "AliyunOSS")
+
+	if fault := s.getFault(req); fault != nil {
+		s.serveFault(w, req, fault, requestID)
+		return
+	}
+	s.serveRequest(w, req, requestID)
+}
+
+// serveRequest parse, check and serve the request
+func (s *Server) serveRequest(w http.ResponseWriter, req *http.Request, requestID string) {
+	var r, err = s.parseRequest(req)
+	if err == nil {
+		err = s.checkAuth(r)
+	}
+	if err == nil {
+		s.locker.Lock()
+		err = s.serve(w, r)
+		s.locker.Unlock()
+	}
+	if err != nil {
+		writeError(w, req, err, requestID)
+	}
+}
+
+// parseRequest get the bucket and object of path, and read the body
+func (s *Server) parseRequest(req *http.Request) (*request, error) {
+	var r = &request{Request: req, query: req.URL.Query()}
+	var path = strings.TrimPrefix(req.URL.EscapedPath(), "/")
+	var parts = strings.SplitN(path, "/", 2)
+	r.bucket = parts[0]
+	if len(parts) == 2 {
+		// the object is escaped by oss.API as a query string
+		var object, err = url.QueryUnescape(parts[1])
+		if err != nil {
+			return nil, newError(http.StatusBadRequest, "InvalidObjectName", "The object name is not valid.")
+		}
+		r.object = object
+	}
+	var body, err = ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, "IncompleteBody", err.Error())
+	}
+	r.body = body
+	return r, nil
+}
+
+// serve dispatch the request to the operation, the server is locked
+func (s *Server) serve(w http.ResponseWriter, r *request) error {
+	if r.bucket == "" {
+		if r.Method == "GET" {
+			return s.listBuckets(w, r)
+		}
+		return errMethodNotAllowed
+	}
+	if r.object == "" {
+		return s.serveBucket(w, r)
+	}
+	return s.serveObject(w, r)
+}
+
+// Error defined the error returned by the server
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	// the extra headers of response
+	Header map[string]string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func newError(statusCode int, code, message string) *Error {
+	return &Error{StatusCode: statusCode, Code: code, Message: message}
+}
+
+var (
+	errNoSuchBucket     = newError(http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist.")
+	errNoSuchKey        = newError(http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+	errNoSuchUpload     = newError(http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+	errAccessDenied     = newError(http.StatusForbidden, "AccessDenied", "You have no right to access this object.")
+	errMethodNotAllowed = newError(http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed.")
+	errMalformedXML     = newError(http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed.")
+	errNotImplemented   = newError(http.StatusNotImplemented, "NotImplemented", "The operation is not implemented by osstest.")
+)
+
+// writeError write the error as OSS error XML, HEAD request has no body
+func writeError(w http.ResponseWriter, req *http.Request, err error, requestID string) {
+	var e, ok = err.(*Error)
+	if !ok {
+		e = newError(http.StatusInternalServerError, "InternalError", err.Error())
+	}
+	for k, v := range e.Header {
+		w.Header().Set(k, v)
+	}
+	var result = struct {
+		XMLName   xml.Name `xml:"Error"`
+		Code      string
+		Message   string
+		RequestID string `xml:"RequestId"`
+		HostID    string `xml:"HostId"`
+	}{Code: e.Code, Message: e.Message, RequestID: requestID, HostID: req.Host}
+	var data, _ = xml.Marshal(result)
+	w.Header().Set("Content-Type", "application/xml")
+	if req.Method == "HEAD" {
+		w.WriteHeader(e.StatusCode)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(xml.Header)+len(data)))
+	w.WriteHeader(e.StatusCode)
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
+
+// writeXML write the result as XML
+func writeXML(w http.ResponseWriter, result interface{}) error {
+	var data, err = xml.Marshal(result)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Length", strconv.Itoa(len(xml.Header)+len(data)))
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+	return nil
+}
+
+// checkAuth check the V1 signature of Authorization header or presigned url by oss.VerifyRequest.
+// The request without signature is anonymous, it is checked by ACL later.
+func (s *Server) checkAuth(r *request) error {
+	var opts = oss.GetDefaultVerifyOptions()
+	opts.MaxClockSkew = s.opts.MaxClockSkew
+	var result, err = oss.VerifyRequest(r.Request, s.lookupSecret, opts)
+	switch err {
+	case nil:
+		r.signed = true
+		return nil
+	case oss.ErrSignatureMissing:
+		return nil
+	case oss.ErrSignatureNotSupported:
+		return newError(http.StatusBadRequest, "InvalidArgument", "osstest only supports the V1 signature.")
+	case oss.ErrSignatureMalformed:
+		return newError(http.StatusBadRequest, "InvalidArgument", "The signature of request is invalid.")
+	case oss.ErrRequestTimeTooSkewed:
+		return ErrRequestTimeTooSkewed
+	case oss.ErrSignatureExpired:
+		return newError(http.StatusForbidden, "AccessDenied", "Request has expired.")
+	case oss.ErrAccessIDNotFound:
+		return newError(http.StatusForbidden, "InvalidAccessKeyId", "The OSS Access Key Id you provided does not exist in our records.")
+	case oss.ErrInvalidSecurityToken:
+		return newError(http.StatusForbidden, "InvalidSecurityToken", "The security token you provided is invalid.")
+	case oss.ErrSignatureDoesNotMatch:
+		return newError(http.StatusForbidden, "SignatureDoesNotMatch",
+			"The request signature we calculated does not match the signature you provided. String to sign: "+
+				strconv.Quote(result.StringToSign))
+	}
+	return err
+}
+
+// lookupSecret get the secret of the credentials accepted by the server
+func (s *Server) lookupSecret(accessID string) (string, string, error) {
+	if accessID != s.opts.AccessID {
+		return "", "", oss.ErrAccessIDNotFound
+	}
+	return s.opts.SecretAccessKey, s.opts.StsToken, nil
+}
+
+// checkContentMD5 check the body with Content-MD5 header if it is sent
+func checkContentMD5(r *request) error {
+	var contentMD5 = r.Header.Get("Content-MD5")
+	if contentMD5 == "" {
+		return nil
+	}
+	if contentMD5 != base64.StdEncoding.EncodeToString(md5Sum(r.body)) {
+		return newError(http.StatusBadRequest, "InvalidDigest", "The Content-MD5 you specified is not valid.")
+	}
+	return nil
+}
+
+// decodeXML unmarshal the body of request
+func decodeXML(r *request, v interface{}) error {
+	if err := xml.NewDecoder(bytes.NewReader(r.body)).Decode(v); err != nil {
+		return errMalformedXML
+	}
+	return nil
+}
@@ -0,0 +1,369 @@
+package osstest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Lupino/oss-go-sdk"
+)
+
+func newTestAPI(t *testing.T) (*Server, *oss.API) {
+	var server = NewServer(nil)
+	var api, err = server.NewAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = api.PutBucket("bucket", oss.ACLPrivate, "", nil); err != nil {
+		t.Fatalf("PutBucket: except: nil, but got: %s\n", err)
+	}
+	return server, api
+}
+
+func getErrorCode(err error) string {
+	if e, ok := err.(*oss.Error); ok {
+		return e.Code
+	}
+	return ""
+}
+
+func TestBucket(t *testing.T) {
+	var server, api = newTestAPI(t)
+	defer server.Close()
+
+	if err := api.PutBucket("bucket-2", oss.ACLPublicRead, "oss-cn-beijing", nil); err != nil {
+		t.Fatalf("PutBucket: except: nil, but got: %s\n", err)
+	}
+	var location oss.LocationConstraint
+	if err := api.GetBucketLocation("bucket-2", &location); err != nil || location != "oss-cn-beijing" {
+		t.Fatalf("GetBucketLocation: except: %s, but got: %s %v\n", "oss-cn-beijing", location, err)
+	}
+	var acl oss.AccessControlPolicy
+	if err := api.GetBucketACL("bucket-2", &acl); err != nil || len(acl.AccessControlList) != 1 ||
+		acl.AccessControlList[0] != "public-read" {
+		t.Fatalf("GetBucketACL: except: %s, but got: %v %v\n", "public-read", acl.AccessControlList, err)
+	}
+
+	var names []string
+	var iter = api.ListBuckets(&oss.ListBucketsOptions{MaxKeys: 1})
+	for iter.Next() {
+		names = append(names, iter.Bucket().Name)
+	}
+	if iter.Err() != nil || strings.Join(names, ",") != "bucket,bucket-2" {
+		t.Fatalf("ListBuckets: except: %s, but got: %s %v\n", "bucket,bucket-2", strings.Join(names, ","), iter.Err())
+	}
+
+	api.PutObject("bucket-2", "object", strings.NewReader("data"), nil)
+	if err := api.DeleteBucket("bucket-2"); getErrorCode(err) != "BucketNotEmpty" {
+		t.Fatalf("DeleteBucket: except: BucketNotEmpty, but got: %v\n", err)
+	}
+	api.DeleteObject("bucket-2", "object")
+	if err := api.DeleteBucket("bucket-2"); err != nil {
+		t.Fatalf("DeleteBucket: except: nil, but got: %s\n", err)
+	}
+	if err := api.GetBucketACL("bucket-2", &acl); getErrorCode(err) != "NoSuchBucket" {
+		t.Fatalf("GetBucketACL: except: NoSuchBucket, but got: %v\n", err)
+	}
+}
+
+func TestObject(t *testing.T) {
+	var server, api = newTestAPI(t)
+	defer server.Close()
+	api.SetEnableCRC64(true)
+
+	var opts = oss.GetDefaultPutObjectOptions()
+	opts.ContentType = "text/plain"
+	opts.UserMeta["author"] = "osstest"
+	if err := api.PutObject("bucket", "dir/hello world+1.txt", strings.NewReader("hello world"), opts.Headers()); err != nil {
+		t.Fatalf("PutObject: except: nil, but got: %s\n", err)
+	}
+	if data, ok := server.Object("bucket", "dir/hello world+1.txt"); !ok || string(data) != "hello world" {
+		t.Fatalf("PutObject: except: %s, but got: %s\n", "hello world", data)
+	}
+
+	var meta, err = api.HeadObject("bucket", "dir/hello world+1.txt", nil)
+	if err != nil {
+		t.Fatalf("HeadObject: except: nil, but got: %s\n", err)
+	}
+	if meta.Size != 11 || meta.ContentType != "text/plain" || meta.UserMeta["author"] != "osstest" ||
+		meta.ObjectType != oss.ObjectTypeNormal {
+		t.Fatalf("HeadObject: got unexcept meta: %+v\n", meta)
+	}
+
+	var getOpts = oss.GetDefaultGetObjectOptions()
+	getOpts.SetRange(6, 10)
+	var reader, _ = api.GetObject("bucket", "dir/hello world+1.txt", getOpts.Headers(), getOpts.Params())
+	var data, _ = ioutil.ReadAll(reader)
+	reader.Close()
+	if string(data) != "world" {
+		t.Fatalf("GetObject: except: %s, but got: %s\n", "world", data)
+	}
+
+	if _, err = api.HeadObject("bucket", "missing", nil); err == nil {
+		t.Fatalf("HeadObject: except: NoSuchKey, but got: nil\n")
+	}
+	if _, err = api.GetObject("bucket", "missing", nil, nil); getErrorCode(err) != "NoSuchKey" {
+		t.Fatalf("GetObject: except: NoSuchKey, but got: %v\n", err)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	var server, api = newTestAPI(t)
+	defer server.Close()
+
+	var options = server.APIOptions()
+	options.SecretAccessKey = "wrong"
+	var wrongAPI, _ = oss.NewAPI(options)
+	if err := wrongAPI.PutObject("bucket", "object", strings.NewReader("data"), nil); getErrorCode(err) != "SignatureDoesNotMatch" {
+		t.Fatalf("PutObject: except: SignatureDoesNotMatch, but got: %v\n", err)
+	}
+	options = server.APIOptions()
+	options.AccessID = "unknown"
+	wrongAPI, _ = oss.NewAPI(options)
+	if err := wrongAPI.PutObject("bucket", "object", strings.NewReader("data"), nil); getErrorCode(err) != "InvalidAccessKeyId" {
+		t.Fatalf("PutObject: except: InvalidAccessKeyId, but got: %v\n", err)
+	}
+
+	// the presigned url is checked too
+	api.PutObject("bucket", "a b/object", strings.NewReader("data"), nil)
+	var signURL, _ = api.Presign("bucket", "a b/object", nil)
+	var res, err = http.Get(signURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || string(data) != "data" {
+		t.Fatalf("Presign: except: %s, but got: %d %s\n", "data", res.StatusCode, data)
+	}
+	if res, err = http.Get(strings.Replace(signURL, "Signature=", "Signature=wrong", 1)); err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Presign: except: %d, but got: %d\n", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestSignatureStsToken(t *testing.T) {
+	var opts = GetDefaultServerOptions()
+	opts.StsToken = "sts-token"
+	var server = NewServer(opts)
+	defer server.Close()
+
+	var api, _ = server.NewAPI()
+	if err := api.PutBucket("bucket", oss.ACLPrivate, "", nil); err != nil {
+		t.Fatalf("PutBucket: except: nil, but got: %s\n", err)
+	}
+	var options = server.APIOptions()
+	options.StsToken = ""
+	var noTokenAPI, _ = oss.NewAPI(options)
+	if err := noTokenAPI.PutBucket("bucket", oss.ACLPrivate, "", nil); getErrorCode(err) != "InvalidSecurityToken" {
+		t.Fatalf("PutBucket: except: InvalidSecurityToken, but got: %v\n", err)
+	}
+}
+
+func TestAnonymousACL(t *testing.T) {
+	var server, api = newTestAPI(t)
+	defer server.Close()
+	api.PutObject("bucket", "object", strings.NewReader("data"), nil)
+
+	var get = func() int {
+		var res, err = http.Get(server.URL + "/bucket/object")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+	if status := get(); status != http.StatusForbidden {
+		t.Fatalf("GetObject: except: %d, but got: %d\n", http.StatusForbidden, status)
+	}
+	api.PutObjectACL("bucket", "object", string(oss.ACLPublicRead))
+	if status := get(); status != http.StatusOK {
+		t.Fatalf("GetObject: except: %d, but got: %d\n", http.StatusOK, status)
+	}
+	var acl oss.AccessControlPolicy
+	if err := api.GetObjectACL("bucket", "object", &acl); err != nil || acl.AccessControlList[0] != "public-read" {
+		t.Fatalf("GetObjectACL: except: %s, but got: %v %v\n", "public-read", acl.AccessControlList, err)
+	}
+	api.PutObjectACL("bucket", "object", "default")
+	api.PutBucketACL("bucket", oss.ACLPublicRead, nil)
+	if status := get(); status != http.StatusOK {
+		t.Fatalf("GetObject: except: %d, but got: %d\n", http.StatusOK, status)
+	}
+}
+
+func TestCopyAndAppend(t *testing.T) {
+	var server, api = newTestAPI(t)
+	defer server.Close()
+
+	api.PutObject("bucket", "source", strings.NewReader("data"), map[string]string{"x-oss-meta-key": "value"})
+	var result, err = api.CopyObject("bucket", "source", "bucket", "target", nil)
+	if err != nil || result.ETag == "" {
+		t.Fatalf("CopyObject: except: nil, but got: %v\n", err)
+	}
+	var meta, _ = api.HeadObject("bucket", "target", nil)
+	if meta.UserMeta["key"] != "value" || meta.Size != 4 {
+		t.Fatalf("CopyObject: got unexcept meta: %+v\n", meta)
+	}
+
+	if meta, err = api.AppendObject("bucket", "log", 0, strings.NewReader("hello "), nil); err != nil {
+		t.Fatalf("AppendObject: except: nil, but got: %s\n", err)
+	}
+	if meta, err = api.AppendObject("bucket", "log", int(meta.NextAppendPosition), strings.NewReader("world"), nil); err != nil {
+		t.Fatalf("AppendObject: except: nil, but got: %s\n", err)
+	}
+	if meta.NextAppendPosition != 11 {
+		t.Fatalf("AppendObject: except: %d, but got: %d\n", 11, meta.NextAppendPosition)
+	}
+	if _, err = api.AppendObject("bucket", "log", 3, strings.NewReader("!"), nil); getErrorCode(err) != "PositionNotEqualToLength" {
+		t.Fatalf("AppendObject: except: PositionNotEqualToLength, but got: %v\n", err)
+	}
+	if _, err = api.AppendObject("bucket", "source", 4, strings.NewReader("!"), nil); getErrorCode(err) != "ObjectNotAppendable" {
+		t.Fatalf("AppendObject: except: ObjectNotAppendable, but got: %v\n", err)
+	}
+	if data, _ := server.Object("bucket", "log"); string(data) != "hello world" {
+		t.Fatalf("AppendObject: except: %s, but got: %s\n", "hello world", data)
+	}
+}
+
+func TestMultipartUpload(t *testing.T) {
+	var server, api = newTestAPI(t)
+	defer server.Close()
+
+	var dir, err = ioutil.TempDir("", "osstest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var data = bytes.Repeat([]byte("0123456789"), oss.MinPartSize/4)
+	var fileName = filepath.Join(dir, "file")
+	ioutil.WriteFile(fileName, data, 0600)
+
+	var uploadOpts = oss.GetDefaultUploadOptions()
+	uploadOpts.PartSize = oss.MinPartSize
+	var result oss.CompleteMultipartUploadResult
+	if result, err = api.UploadFile("bucket", "large", fileName, uploadOpts); err != nil {
+		t.Fatalf("UploadFile: except: nil, but got: %s\n", err)
+	}
+	if !strings.HasSuffix(result.ETag, "-3\"") {
+		t.Fatalf("UploadFile: except ETag of 3 parts, but got: %s\n", result.ETag)
+	}
+
+	var downloadOpts = oss.GetDefaultDownloadOptions()
+	downloadOpts.PartSize = oss.MinPartSize / 3
+	if err = api.DownloadFile("bucket", "large", filepath.Join(dir, "download"), downloadOpts); err != nil {
+		t.Fatalf("DownloadFile: except: nil, but got: %s\n", err)
+	}
+	if downloaded, _ := ioutil.ReadFile(filepath.Join(dir, "download")); !bytes.Equal(downloaded, data) {
+		t.Fatalf("DownloadFile: the file is not equal to the object\n")
+	}
+
+	var multi, _ = api.NewMultipartUpload("bucket", "copy", nil)
+	if _, err = multi.CopyPart("bucket", "large", 1, "bytes=0-9", nil); err != nil {
+		t.Fatalf("CopyPart: except: nil, but got: %s\n", err)
+	}
+	var uploads = api.ListUploads("bucket", nil)
+	if !uploads.Next() || uploads.Upload().UploadID != multi.UploadID || uploads.Next() {
+		t.Fatalf("ListUploads: except: %s, but got: %v\n", multi.UploadID, uploads.Err())
+	}
+	var parts oss.ListPartsResult
+	if err = multi.ListParts(0, 0, &parts); err != nil || len(parts.Parts) != 1 || parts.Parts[0].Size != 10 {
+		t.Fatalf("ListParts: except: 1 part, but got: %+v %v\n", parts.Parts, err)
+	}
+	if err = multi.CompleteUpload([]oss.Part{{PartNumber: 1, ETag: "\"wrong\""}}, nil); getErrorCode(err) != "InvalidPart" {
+		t.Fatalf("CompleteUpload: except: InvalidPart, but got: %v\n", err)
+	}
+	multi.AbortUpload()
+	if err = multi.AbortUpload(); getErrorCode(err) != "NoSuchUpload" {
+		t.Fatalf("AbortUpload: except: NoSuchUpload, but got: %v\n", err)
+	}
+}
+
+func TestListObjects(t *testing.T) {
+	var server, api = newTestAPI(t)
+	defer server.Close()
+	for _, key := range []string{"a/1", "a/2", "b/1", "c", "d/1/1", "e"} {
+		api.PutObject("bucket", key, strings.NewReader(key), nil)
+	}
+
+	var keys []string
+	var iter = api.ListObjects("bucket", &oss.ListObjectsOptions{Delimiter: "/", MaxKeys: 2, EncodingType: "url"})
+	for iter.Next() {
+		keys = append(keys, iter.Object().Key)
+	}
+	if iter.Err() != nil || strings.Join(keys, ",") != "c,e" || strings.Join(iter.Prefixes(), ",") != "a/,b/,d/" {
+		t.Fatalf("ListObjects: except: c,e a/,b/,d/, but got: %s %s %v\n", strings.Join(keys, ","),
+			strings.Join(iter.Prefixes(), ","), iter.Err())
+	}
+
+	keys = nil
+	var iterV2 = api.ListObjectsV2("bucket", &oss.ListObjectsV2Options{Prefix: "a/", StartAfter: "a/1", MaxKeys: 1})
+	for iterV2.Next() {
+		keys = append(keys, iterV2.Object().Key)
+	}
+	if iterV2.Err() != nil || strings.Join(keys, ",") != "a/2" {
+		t.Fatalf("ListObjectsV2: except: a/2, but got: %s %v\n", strings.Join(keys, ","), iterV2.Err())
+	}
+
+	var deleted, err = api.DeletePrefix("bucket", "d/", nil)
+	if err != nil || len(deleted.Objects) != 1 {
+		t.Fatalf("DeletePrefix: except: 1 deleted, but got: %v %v\n", deleted, err)
+	}
+	if _, ok := server.Object("bucket", "d/1/1"); ok {
+		t.Fatalf("DeletePrefix: the object is not deleted\n")
+	}
+}
+
+func TestBucketConfig(t *testing.T) {
+	var server, api = newTestAPI(t)
+	defer server.Close()
+
+	var cors oss.CORSConfiguration
+	if err := api.GetBucketCORS("bucket", &cors); getErrorCode(err) != "NoSuchCORSConfiguration" {
+		t.Fatalf("GetBucketCORS: except: NoSuchCORSConfiguration, but got: %v\n", err)
+	}
+	var rule = oss.CORSRule{
+		AllowedOrigin: []string{"http://*.example.com"},
+		AllowedMethod: []string{"GET", "PUT"},
+		AllowedHeader: []string{"x-oss-*"},
+		MaxAgeSeconds: 100,
+	}
+	if err := api.PutBucketCORS("bucket", oss.CORSConfiguration{Rules: []oss.CORSRule{rule}}); err != nil {
+		t.Fatalf("PutBucketCORS: except: nil, but got: %s\n", err)
+	}
+	if err := api.GetBucketCORS("bucket", &cors); err != nil || len(cors.Rules) != 1 {
+		t.Fatalf("GetBucketCORS: except: 1 rule, but got: %+v %v\n", cors, err)
+	}
+
+	var headers = map[string]string{
+		"Origin":                         "http://www.example.com",
+		"Access-Control-Request-Method":  "PUT",
+		"Access-Control-Request-Headers": "x-oss-meta-key",
+	}
+	var meta, err = api.OptionObject("bucket", "object", headers)
+	if err != nil || meta.Header.Get("Access-Control-Allow-Origin") != "http://www.example.com" {
+		t.Fatalf("OptionObject: except: allowed, but got: %v %v\n", meta, err)
+	}
+	headers["Origin"] = "http://www.other.com"
+	if _, err = api.OptionObject("bucket", "object", headers); getErrorCode(err) != "AccessForbidden" {
+		t.Fatalf("OptionObject: except: AccessForbidden, but got: %v\n", err)
+	}
+
+	if err = api.PutBucketLifecycle("bucket", oss.LifecycleRule{ID: "rule", Prefix: "tmp/", Status: "Enabled", ExpirationDays: 1}); err != nil {
+		t.Fatalf("PutBucketLifecycle: except: nil, but got: %s\n", err)
+	}
+	var lifecycle oss.LifecycleConfiguration
+	if err = api.GetBucketLifecycle("bucket", &lifecycle); err != nil || lifecycle.Rule.ExpirationDays != 1 {
+		t.Fatalf("GetBucketLifecycle: except: 1 day, but got: %+v %v\n", lifecycle, err)
+	}
+	api.DeleteBucketLifecycle("bucket")
+	if err = api.GetBucketLifecycle("bucket", &lifecycle); getErrorCode(err) != "NoSuchLifecycle" {
+		t.Fatalf("GetBucketLifecycle: except: NoSuchLifecycle, but got: %v\n", err)
+	}
+}
@@ -0,0 +1,248 @@
+package oss
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"sort"
+	"time"
+)
+
+// PostPolicy defined the policy of browser POST upload, the fields with zero value are ignored.
+type PostPolicy struct {
+	// the form is valid until Expiration
+	Expiration time.Time
+	// the object name must be Key
+	Key string
+	// the object name must start with KeyStartsWith
+	KeyStartsWith string
+	// the file size must in [ContentLengthMin, ContentLengthMax]
+	ContentLengthMin int64
+	ContentLengthMax int64
+	// the Content-Type field must be ContentType
+	ContentType string
+	// the Content-Type field must start with ContentTypeStartsWith
+	ContentTypeStartsWith string
+	// other conditions, e.g.: map[string]string{"success_action_status": "201"}
+	// or []string{"starts-with", "$x-oss-meta-user", "u_"}
+	Conditions []interface{}
+}
+
+// GetDefaultPostPolicy get default post policy, it expires in one hour
+func GetDefaultPostPolicy() *PostPolicy {
+	return &PostPolicy{
+		Expiration: time.Now().Add(time.Hour),
+	}
+}
+
+// PostPolicyForm defined the signed form of browser POST upload
+type PostPolicyForm struct {
+	// the url the form POST to
+	URL string
+	// the bucket of url
+	Bucket string
+	// the fields of form, the file field must be after them
+	Fields map[string]string
+}
+
+// SignPostPolicy create the signed form of policy for the bucket,
+// the browser can upload file with it directly.
+func (api *API) SignPostPolicy(bucket string, policy *PostPolicy) (*PostPolicyForm, error) {
+	return api.SignPostPolicyWithContext(context.Background(), bucket, policy)
+}
+
+// SignPostPolicyWithContext is like SignPostPolicy, but with a context to get the credentials.
+func (api *API) SignPostPolicyWithContext(ctx context.Context, bucket string, policy *PostPolicy) (*PostPolicyForm, error) {
+	var creds, err = api.credentials.GetCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields = make(map[string]string)
+	var conditions = []interface{}{map[string]string{"bucket": bucket}}
+	if policy.Key != "" {
+		conditions = append(conditions, []string{"eq", "$key", policy.Key})
+		fields["key"] = policy.Key
+	}
+	if policy.KeyStartsWith != "" {
+		conditions = append(conditions, []string{"starts-with", "$key", policy.KeyStartsWith})
+	}
+	if policy.ContentLengthMax > 0 {
+		conditions = append(conditions, []interface{}{"content-length-range",
+			policy.ContentLengthMin, policy.ContentLengthMax})
+	}
+	if policy.ContentType != "" {
+		conditions = append(conditions, []string{"eq", "$Content-Type", policy.ContentType})
+		fields["Content-Type"] = policy.ContentType
+	}
+	if policy.ContentTypeStartsWith != "" {
+		conditions = append(conditions, []string{"starts-with", "$Content-Type", policy.ContentTypeStartsWith})
+	}
+	conditions = append(conditions, policy.Conditions...)
+
+	var now = time.Now()
+	if len(creds.StsToken) > 0 {
+		fields["x-oss-security-token"] = creds.StsToken
+		conditions = append(conditions, map[string]string{"x-oss-security-token": creds.StsToken})
+	}
+	if api.signVersion == SignVersionV4 {
+		fields["x-oss-signature-version"] = V4Algorithm
+		fields["x-oss-credential"] = fmt.Sprintf("%s/%s", creds.AccessID, getScopeV4(now, api.region))
+		fields["x-oss-date"] = now.UTC().Format(v4TimeFormat)
+		for _, k := range []string{"x-oss-signature-version", "x-oss-credential", "x-oss-date"} {
+			conditions = append(conditions, map[string]string{k: fields[k]})
+		}
+	}
+
+	var expiration = policy.Expiration
+	if expiration.IsZero() {
+		expiration = now.Add(time.Hour)
+	}
+	var data []byte
+	if data, err = json.Marshal(map[string]interface{}{
+		"expiration": expiration.UTC().Format("2006-01-02T15:04:05.000Z"),
+		"conditions": conditions,
+	}); err != nil {
+		return nil, err
+	}
+	var encoded = base64.StdEncoding.EncodeToString(data)
+	fields["policy"] = encoded
+
+	if api.signVersion == SignVersionV4 {
+		// the string to sign of V4 POST is the encoded policy
+		var signingKey = getSigningKeyV4(creds.SecretAccessKey, api.region, now)
+		fields["x-oss-signature"] = hex.EncodeToString(hmacSHA256(signingKey, encoded))
+	} else {
+		var h = hmac.New(sha1.New, []byte(creds.SecretAccessKey))
+		h.Write([]byte(encoded))
+		fields["OSSAccessKeyId"] = creds.AccessID
+		fields["Signature"] = base64.StdEncoding.EncodeToString(h.Sum(nil))
+	}
+
+	return &PostPolicyForm{
+		URL:    api.getObjectURL(bucket, ""),
+		Bucket: bucket,
+		Fields: fields,
+	}, nil
+}
+
+// SubmitPostForm upload the body as the file field of a multipart/form-data POST,
+// just like what a browser does with the form.
+//
+//   - form: the signed form, its fields are never changed
+//   - fields: the fields add to or override the form fields, e.g.: key, x-oss-meta-*
+//   - body: content of the file
+//
+// The failed POST is retried by the RetryPolicy of api if the body can be rewound, e.g.: *bytes.Reader, *os.File.
+func (api *API) SubmitPostForm(form *PostPolicyForm, fields map[string]string, body io.Reader) error {
+	return api.SubmitPostFormWithContext(context.Background(), form, fields, body)
+}
+
+// SubmitPostFormWithContext is like SubmitPostForm, but with a context to cancel the request.
+func (api *API) SubmitPostFormWithContext(ctx context.Context, form *PostPolicyForm,
+	fields map[string]string, body io.Reader) (err error) {
+
+	var allFields = copyMap(form.Fields)
+	for k, v := range fields {
+		allFields[k] = v
+	}
+	var keys = make([]string, 0, len(allFields))
+	for k := range allFields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf = bytes.NewBuffer(nil)
+	var writer = multipart.NewWriter(buf)
+	for _, k := range keys {
+		if err = writer.WriteField(k, allFields[k]); err != nil {
+			return err
+		}
+	}
+	// the file field must be the last one
+	if _, err = writer.CreateFormFile("file", path.Base(allFields["key"])); err != nil {
+		return err
+	}
+	var header = append([]byte(nil), buf.Bytes()...)
+	buf.Reset()
+	writer.Close()
+	var footer = buf.Bytes()
+
+	var length, known = getReaderLength(body)
+	// the body is rewound before each retry, a body can not rewind is sent only once
+	var bodySeeker io.Seeker
+	var bodyOffset int64
+	if seeker, ok := body.(io.Seeker); ok {
+		if bodyOffset, err = seeker.Seek(0, io.SeekCurrent); err == nil {
+			bodySeeker = seeker
+		}
+	}
+
+	var policy = api.retryPolicy
+	for attempt := 0; ; attempt++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if attempt > 0 && bodySeeker != nil {
+			if _, err = bodySeeker.Seek(bodyOffset, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		if api.qpsLimiter != nil {
+			if err = api.qpsLimiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		var reqBody = io.MultiReader(bytes.NewReader(header), body, bytes.NewReader(footer))
+		if api.uploadLimiter != nil {
+			reqBody = &rateLimitReader{reader: reqBody, limiter: api.uploadLimiter, ctx: ctx}
+		}
+		var req *http.Request
+		if req, err = http.NewRequestWithContext(ctx, "POST", form.URL, reqBody); err != nil {
+			return err
+		}
+		if known {
+			req.ContentLength = int64(len(header)) + length + int64(len(footer))
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("User-Agent", api.agent)
+
+		var res *http.Response
+		if res, err = api.doRequest(req); err == nil {
+			if res.StatusCode/100 == 2 {
+				drainAndClose(res.Body)
+				return nil
+			}
+			var errStr, _ = ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			err = parseResponseError(res, errStr, "POST", form.Bucket, allFields["key"])
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt+1 >= policy.MaxAttempts() || (body != nil && bodySeeker == nil) ||
+			!policy.ShouldRetry(attempt, res, err) {
+			return
+		}
+		var delay = policy.RetryDelay(attempt, res)
+		if api.debug {
+			log.Printf("POST %s failed: %v, retry after %s\n", form.URL, err, delay)
+		}
+		if err = sleepWithContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
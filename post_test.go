@@ -0,0 +1,136 @@
+package oss
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSignPostPolicy(t *testing.T) {
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(mockServer.URL)
+	options.AccessID, options.SecretAccessKey, options.StsToken = "id", "secret", "token"
+	var api, _ = NewAPI(options)
+	var policy = GetDefaultPostPolicy()
+	policy.KeyStartsWith = "user/"
+	policy.ContentLengthMax = 1024
+	policy.ContentType = "image/png"
+	var form, err = api.SignPostPolicy("bucket", policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(form.URL, "/bucket/") {
+		t.Fatalf("SignPostPolicy: got unexcept url: %s\n", form.URL)
+	}
+
+	var h = hmac.New(sha1.New, []byte("secret"))
+	h.Write([]byte(form.Fields["policy"]))
+	var except = base64.StdEncoding.EncodeToString(h.Sum(nil))
+	if form.Fields["Signature"] != except {
+		t.Fatalf("SignPostPolicy: Signature except: %s, but got: %s\n", except, form.Fields["Signature"])
+	}
+	if form.Fields["OSSAccessKeyId"] != "id" || form.Fields["x-oss-security-token"] != "token" ||
+		form.Fields["Content-Type"] != "image/png" {
+		t.Fatalf("SignPostPolicy: got unexcept fields: %v\n", form.Fields)
+	}
+
+	var data, _ = base64.StdEncoding.DecodeString(form.Fields["policy"])
+	var decoded struct {
+		Expiration string
+		Conditions []interface{}
+	}
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	var conditions, _ = json.Marshal(decoded.Conditions)
+	except = `[{"bucket":"bucket"},["starts-with","$key","user/"],["content-length-range",0,1024],` +
+		`["eq","$Content-Type","image/png"],{"x-oss-security-token":"token"}]`
+	if string(conditions) != except {
+		t.Fatalf("SignPostPolicy: conditions except: %s, but got: %s\n", except, conditions)
+	}
+}
+
+func TestSubmitPostForm(t *testing.T) {
+	var fields = make(map[string]string)
+	var file []byte
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := req.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "<Error><Code>InvalidArgument</Code><Message>%s</Message></Error>", err)
+			return
+		}
+		for k := range req.MultipartForm.Value {
+			fields[k] = req.FormValue(k)
+		}
+		var fp, _, _ = req.FormFile("file")
+		file, _ = ioutil.ReadAll(fp)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	options.AccessID = "id"
+	options.SecretAccessKey = "secret"
+	var api, _ = NewAPI(options)
+
+	var body = bytes.NewReader([]byte("this is the body"))
+	if err := api.PostObject("bucket", "user/object", body, map[string]string{"x-oss-meta-user": "u_1"}); err != nil {
+		t.Fatal(err)
+	}
+	if string(file) != "this is the body" {
+		t.Fatalf("PostObject: file except: %s, but got: %s\n", "this is the body", file)
+	}
+	if fields["key"] != "user/object" || fields["x-oss-meta-user"] != "u_1" || fields["policy"] == "" {
+		t.Fatalf("PostObject: got unexcept fields: %v\n", fields)
+	}
+}
+
+func TestSubmitPostFormRetry(t *testing.T) {
+	var attempts = 0
+	var file []byte
+	var postAPI, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		attempts++
+		var fp, _, err = req.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		file, _ = ioutil.ReadAll(fp)
+		if attempts < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, "<Error><Code>ServiceUnavailable</Code></Error>")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer closeServer()
+
+	var form, _ = postAPI.SignPostPolicy("bucket", GetDefaultPostPolicy())
+	var fields = map[string]string{"key": "object"}
+	if err := postAPI.SubmitPostForm(form, fields, strings.NewReader("this is the body")); err != nil {
+		t.Fatalf("SubmitPostForm: except: nil, but got: %s\n", err)
+	}
+	if attempts != 2 || string(file) != "this is the body" {
+		t.Fatalf("SubmitPostForm: except: %d attempts of %s, but got: %d %s\n", 2, "this is the body", attempts, file)
+	}
+
+	// the body can not rewind is sent only once
+	attempts = 0
+	var err = postAPI.SubmitPostForm(form, fields, struct{ io.Reader }{strings.NewReader("this is the body")})
+	var realErr *Error
+	if !errors.As(err, &realErr) || realErr.Bucket != "bucket" || realErr.Object != "object" || attempts != 1 {
+		t.Fatalf("SubmitPostForm: except: ServiceUnavailable of bucket/object by %d attempt, but got: %v %d\n",
+			1, err, attempts)
+	}
+}
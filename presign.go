@@ -0,0 +1,154 @@
+package oss
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PresignOptions defined the options of presigned url
+type PresignOptions struct {
+	// one of GET, PUT, HEAD, POST, DELETE, default is GET
+	Method string
+	// the url is valid within Expires, default is 60s
+	Expires time.Duration
+	// the Content-Type and Content-MD5 the PUT request must send
+	ContentType string
+	ContentMD5  string
+	// other headers the request must send, e.g.: x-oss-meta-*
+	Headers map[string]string
+	// other query params, e.g.: acl, uploadId, partNumber
+	Params map[string]string
+	// override the response headers of GET request
+	ResponseContentType        string
+	ResponseContentLanguage    string
+	ResponseContentDisposition string
+	ResponseContentEncoding    string
+	ResponseCacheControl       string
+	ResponseExpires            string
+	// image or video process of GET request, e.g.: image/resize,w_100
+	Process string
+	// custom domain (CNAME) bound to the bucket, e.g.: cdn.example.com,
+	// the url is http(s)://Host/object
+	Host string
+}
+
+// GetDefaultPresignOptions get default presign options
+func GetDefaultPresignOptions() *PresignOptions {
+	return &PresignOptions{
+		Method:  "GET",
+		Expires: 60 * time.Second,
+	}
+}
+
+// Presign create a presigned url of object, the url can be used without credentials until it expires.
+// The opts is never changed, nil means GetDefaultPresignOptions().
+func (api *API) Presign(bucket, object string, opts *PresignOptions) (string, error) {
+	return api.PresignWithContext(context.Background(), bucket, object, opts)
+}
+
+// PresignWithContext is like Presign, but with a context to get the credentials.
+func (api *API) PresignWithContext(ctx context.Context, bucket, object string, opts *PresignOptions) (string, error) {
+	if opts == nil {
+		opts = GetDefaultPresignOptions()
+	}
+	var creds, err = api.credentials.GetCredentials(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var method = strings.ToUpper(opts.Method)
+	if method == "" {
+		method = "GET"
+	}
+	var expires = opts.Expires
+	if expires <= 0 {
+		expires = 60 * time.Second
+	}
+
+	var headers = copyMap(opts.Headers)
+	if opts.ContentType != "" {
+		headers["Content-Type"] = opts.ContentType
+	}
+	if opts.ContentMD5 != "" {
+		headers["Content-MD5"] = opts.ContentMD5
+	}
+
+	var params = copyMap(opts.Params)
+	var overrides = map[string]string{
+		"response-content-type":        opts.ResponseContentType,
+		"response-content-language":    opts.ResponseContentLanguage,
+		"response-content-disposition": opts.ResponseContentDisposition,
+		"response-content-encoding":    opts.ResponseContentEncoding,
+		"response-cache-control":       opts.ResponseCacheControl,
+		"response-expires":             opts.ResponseExpires,
+		"x-oss-process":                opts.Process,
+	}
+	for k, v := range overrides {
+		if v != "" {
+			params[k] = v
+		}
+	}
+
+	api.presign(creds, method, bucket, object, headers, params, time.Now(), expires)
+
+	var uri = api.getObjectURL(bucket, object)
+	if opts.Host != "" {
+		uri = fmt.Sprintf("%s://%s/%s", api.getSchema(), opts.Host, v4Escape(object, true))
+	}
+	return appendParam(uri, params), nil
+}
+
+// presign NOT public API
+// Set the signature query params into params with the sign version of api.
+func (api *API) presign(creds *Credentials, method, bucket, object string,
+	headers, params map[string]string, now time.Time, expires time.Duration) {
+
+	if api.signVersion == SignVersionV4 {
+		api.presignV4(creds, method, bucket, object, headers, params, now, expires)
+		return
+	}
+
+	if len(creds.StsToken) > 0 {
+		params["security-token"] = creds.StsToken
+	}
+	// the Date of string to sign is the expire unix timestamp
+	var expireTime = strconv.FormatInt(now.Add(expires).Unix(), 10)
+	headers["Date"] = expireTime
+	var resource = fmt.Sprintf("/%s/%s%s", bucket, object, getResource(params))
+	if bucket == "" {
+		resource = "/" + getResource(params)
+	}
+	params["OSSAccessKeyId"] = creds.AccessID
+	params["Expires"] = expireTime
+	params["Signature"] = getAssign(creds.SecretAccessKey, method, headers, resource, nil, api.debug)
+}
+
+func (api *API) getSchema() string {
+	if api.isSecurity || api.port == 443 {
+		return "https"
+	}
+	return "http"
+}
+
+// getObjectURL get the url of object without query string
+func (api *API) getObjectURL(bucket, object string) string {
+	var schema = api.getSchema()
+	var host = api.host
+	if api.port != 80 && api.port != 443 {
+		host = fmt.Sprintf("%s:%d", api.host, api.port)
+	}
+	object = v4Escape(object, true)
+	if isIP(api.host) {
+		return fmt.Sprintf("%s://%s/%s/%s", schema, host, bucket, object)
+	}
+	if isOSSHost(api.host, api.isOSSDomain) {
+		if checkBucketValid(bucket) {
+			return fmt.Sprintf("%s://%s.%s/%s", schema, bucket, host, object)
+		}
+		return fmt.Sprintf("%s://%s/%s/%s", schema, host, bucket, object)
+	}
+	return fmt.Sprintf("%s://%s/%s", schema, host, object)
+}
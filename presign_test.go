@@ -0,0 +1,106 @@
+package oss
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPresign(t *testing.T) {
+	var apiOptions = GetDefaultAPIOptioins()
+	apiOptions.Host, apiOptions.Port = getHostFromURL(mockServer.URL)
+	apiOptions.AccessID, apiOptions.SecretAccessKey, apiOptions.StsToken = "id", "secret", "token"
+	var api, _ = NewAPI(apiOptions)
+	var opts = GetDefaultPresignOptions()
+	opts.ResponseContentDisposition = "attachment; filename=a.txt"
+	opts.Params = map[string]string{"other": "other"}
+	var signURL, err = api.Presign("bucket", "dir/a b.txt", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.Params) != 1 || opts.Headers != nil {
+		t.Fatalf("Presign: the options is changed: %v\n", opts)
+	}
+
+	var u, _ = url.Parse(signURL)
+	if u.EscapedPath() != "/bucket/dir/a%20b.txt" {
+		t.Fatalf("Presign: got unexcept path: %s\n", u.EscapedPath())
+	}
+	var query = u.Query()
+	var expires, _ = strconv.ParseInt(query.Get("Expires"), 10, 64)
+	var now = time.Now().Unix()
+	if expires < now+50 || expires > now+70 {
+		t.Fatalf("Presign: Expires except about: %d, but got: %s\n", now+60, query.Get("Expires"))
+	}
+	if query.Get("OSSAccessKeyId") != "id" || query.Get("security-token") != "token" {
+		t.Fatalf("Presign: got unexcept query: %v\n", query)
+	}
+	var resource = "/bucket/dir/a b.txt?response-content-disposition=attachment; filename=a.txt&security-token=token"
+	var except = getAssign("secret", "GET", map[string]string{"Date": query.Get("Expires")}, resource, nil, false)
+	if query.Get("Signature") != except {
+		t.Fatalf("Presign: Signature except: %s, but got: %s\n", except, query.Get("Signature"))
+	}
+}
+
+func TestPresignPut(t *testing.T) {
+	var apiOptions = GetDefaultAPIOptioins()
+	apiOptions.Host, apiOptions.Port = getHostFromURL(mockServer.URL)
+	apiOptions.AccessID, apiOptions.SecretAccessKey = "id", "secret"
+	var api, _ = NewAPI(apiOptions)
+	var opts = GetDefaultPresignOptions()
+	opts.Method = "PUT"
+	opts.ContentType = "text/plain"
+	opts.ContentMD5 = "eB5eJF1ptWaXm4bijSPyxw=="
+	opts.Host = "cdn.example.com"
+	var signURL, err = api.Presign("bucket", "object", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var u, _ = url.Parse(signURL)
+	if u.Host != "cdn.example.com" || u.Path != "/object" {
+		t.Fatalf("Presign: got unexcept url: %s\n", signURL)
+	}
+	var query = u.Query()
+	if query.Get("security-token") != "" {
+		t.Fatalf("Presign: except no security-token, but got: %s\n", query.Get("security-token"))
+	}
+	var headers = map[string]string{
+		"Content-Type": opts.ContentType,
+		"Content-MD5":  opts.ContentMD5,
+		"Date":         query.Get("Expires"),
+	}
+	var except = getAssign("secret", "PUT", headers, "/bucket/object", nil, false)
+	if query.Get("Signature") != except {
+		t.Fatalf("Presign: Signature except: %s, but got: %s\n", except, query.Get("Signature"))
+	}
+}
+
+func TestSignURLNotChangeOptions(t *testing.T) {
+	var apiOptions = GetDefaultAPIOptioins()
+	apiOptions.Host, apiOptions.Port = getHostFromURL(mockServer.URL)
+	apiOptions.AccessID, apiOptions.SecretAccessKey = "id", "secret"
+	var api, _ = NewAPI(apiOptions)
+	var options = GetDefaultSignURLOptions()
+	options.Bucket = "bucket"
+	options.Object = "object"
+	var signURL = api.SignURL(options)
+	if len(options.Params) != 0 || len(options.Headers) != 0 || options.Object != "object" {
+		t.Fatalf("SignURL: the options is changed: %v\n", options)
+	}
+	var u, _ = url.Parse(signURL)
+	if _, err := strconv.ParseInt(u.Query().Get("Expires"), 10, 64); err != nil {
+		t.Fatalf("SignURL: Expires is not a unix timestamp: %s\n", u.Query().Get("Expires"))
+	}
+
+	options.URL = "http://example.com/bucket/object"
+	options.Resource = "/bucket/object"
+	signURL = api.SignURLAuthWithExpireTime(options)
+	if len(options.Params) != 0 || len(options.Headers) != 0 {
+		t.Fatalf("SignURLAuthWithExpireTime: the options is changed: %v\n", options)
+	}
+	if !strings.HasPrefix(signURL, options.URL+"?") {
+		t.Fatalf("SignURLAuthWithExpireTime: got unexcept url: %s\n", signURL)
+	}
+}
@@ -0,0 +1,218 @@
+package oss
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ProgressEventType defined the type of progress event
+type ProgressEventType int
+
+const (
+	// ProgressStarted the transfer is started
+	ProgressStarted ProgressEventType = iota
+	// ProgressData some bytes are transferred
+	ProgressData
+	// ProgressPartCompleted a part of parallel upload or download is completed
+	ProgressPartCompleted
+	// ProgressCompleted the transfer is completed
+	ProgressCompleted
+	// ProgressFailed the transfer is failed
+	ProgressFailed
+)
+
+// ProgressEvent defined the progress of a transfer
+type ProgressEvent struct {
+	Type ProgressEventType
+	// the bytes transferred so far
+	ConsumedBytes int64
+	// the bytes of the whole transfer, -1 means unknown
+	TotalBytes int64
+	// the bytes of this ProgressData event,
+	// it is negative when the bytes are sent again by retry
+	RWBytes int64
+	// the part number of ProgressPartCompleted event
+	PartNumber int
+	// the error of ProgressFailed event
+	Err error
+}
+
+// ProgressListener listen the progress of transfer, the events of one transfer are published serially
+type ProgressListener interface {
+	ProgressChanged(event *ProgressEvent)
+}
+
+// ProgressListenerFunc is an adapter to use a function as ProgressListener
+type ProgressListenerFunc func(event *ProgressEvent)
+
+// ProgressChanged call f(event)
+func (f ProgressListenerFunc) ProgressChanged(event *ProgressEvent) {
+	f(event)
+}
+
+// errBodyClosed the error of failed event when the response body is closed before it is read to the end
+var errBodyClosed = errors.New("oss: the body is closed before it is read to the end")
+
+type progressListenerKey struct{}
+
+// WithProgressListener return a copy of ctx with the listener, it is the fallback of the Progress options:
+// PutObjectOptions, GetObjectOptions, UploadOptions, DownloadOptions and MultipartUpload.
+// The transfers without the options report progress to it, e.g.: PutObjectWithContext, AppendObjectWithContext,
+// GetObjectWithContext and UploadLargeFileWithContext.
+func WithProgressListener(ctx context.Context, listener ProgressListener) context.Context {
+	return context.WithValue(ctx, progressListenerKey{}, listener)
+}
+
+// getProgressListener get the listener, or the one of ctx if it is nil
+func getProgressListener(ctx context.Context, listener ProgressListener) ProgressListener {
+	if listener != nil || ctx == nil {
+		return listener
+	}
+	listener, _ = ctx.Value(progressListenerKey{}).(ProgressListener)
+	return listener
+}
+
+// progressTracker publish the events of one transfer, it is safe for concurrent use.
+// All the methods of nil tracker do nothing.
+type progressTracker struct {
+	locker   sync.Mutex
+	listener ProgressListener
+	consumed int64
+	total    int64
+	finished bool
+}
+
+// newProgressTracker create a tracker, nil if listener is nil
+func newProgressTracker(listener ProgressListener, total int64) *progressTracker {
+	if listener == nil {
+		return nil
+	}
+	return &progressTracker{listener: listener, total: total}
+}
+
+func (t *progressTracker) publish(event ProgressEvent) {
+	if t == nil {
+		return
+	}
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	if t.finished {
+		return
+	}
+	t.consumed += event.RWBytes
+	event.ConsumedBytes = t.consumed
+	event.TotalBytes = t.total
+	if event.Type == ProgressCompleted || event.Type == ProgressFailed {
+		t.finished = true
+	}
+	t.listener.ProgressChanged(&event)
+}
+
+func (t *progressTracker) started() {
+	t.publish(ProgressEvent{Type: ProgressStarted})
+}
+
+func (t *progressTracker) data(n int64) {
+	if n != 0 {
+		t.publish(ProgressEvent{Type: ProgressData, RWBytes: n})
+	}
+}
+
+func (t *progressTracker) partCompleted(partNumber int) {
+	t.publish(ProgressEvent{Type: ProgressPartCompleted, PartNumber: partNumber})
+}
+
+func (t *progressTracker) completed() {
+	t.publish(ProgressEvent{Type: ProgressCompleted})
+}
+
+func (t *progressTracker) failed(err error) {
+	t.publish(ProgressEvent{Type: ProgressFailed, Err: err})
+}
+
+// finish publish completed if err is nil, or failed
+func (t *progressTracker) finish(err error) {
+	if err == nil {
+		t.completed()
+	} else {
+		t.failed(err)
+	}
+}
+
+// skip count the bytes transferred before without event, e.g.: the parts resumed from checkpoint
+func (t *progressTracker) skip(n int64) {
+	if t == nil {
+		return
+	}
+	t.locker.Lock()
+	t.consumed += n
+	t.locker.Unlock()
+}
+
+// partContext return a ctx for the requests of a part, their data events are added to the tracker
+func (t *progressTracker) partContext(ctx context.Context) context.Context {
+	if t == nil {
+		return ctx
+	}
+	return WithProgressListener(ctx, ProgressListenerFunc(func(event *ProgressEvent) {
+		if event.Type == ProgressData {
+			t.data(event.RWBytes)
+		}
+	}))
+}
+
+// progressReader report the bytes read to the tracker
+type progressReader struct {
+	reader  io.Reader
+	tracker *progressTracker
+	read    int64
+	// publish completed or failed when the reader is read to the end, only for response body
+	finish bool
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	var n, err = r.reader.Read(p)
+	r.read += int64(n)
+	r.tracker.data(int64(n))
+	if r.finish && err != nil {
+		if err == io.EOF {
+			r.tracker.completed()
+		} else {
+			r.tracker.failed(err)
+		}
+	}
+	return n, err
+}
+
+// Seek seek the underlying reader, the bytes read are taken back when it is rewound for retry.
+func (r *progressReader) Seek(offset int64, whence int) (int64, error) {
+	var seeker, ok = r.reader.(io.Seeker)
+	if !ok {
+		return 0, errors.New("oss: body is not seekable")
+	}
+	if offset != 0 || whence != io.SeekCurrent {
+		r.tracker.data(-r.read)
+		r.read = 0
+	}
+	return seeker.Seek(offset, whence)
+}
+
+// progressReadCloser is progressReader of response body
+type progressReadCloser struct {
+	progressReader
+	closer io.Closer
+}
+
+// Close publish failed if the body is closed before the total bytes are read
+func (r *progressReadCloser) Close() error {
+	if r.finish {
+		if r.tracker.total >= 0 && r.read >= r.tracker.total {
+			r.tracker.completed()
+		} else {
+			r.tracker.failed(errBodyClosed)
+		}
+	}
+	return r.closer.Close()
+}
@@ -0,0 +1,167 @@
+package oss
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// progressRecorder record all the events
+type progressRecorder struct {
+	locker sync.Mutex
+	events []ProgressEvent
+}
+
+func (r *progressRecorder) ProgressChanged(event *ProgressEvent) {
+	r.locker.Lock()
+	r.events = append(r.events, *event)
+	r.locker.Unlock()
+}
+
+func (r *progressRecorder) count(eventType ProgressEventType) int {
+	var count int
+	for _, event := range r.events {
+		if event.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+// check the events start with ProgressStarted and end with ProgressCompleted of total bytes
+func (r *progressRecorder) check(t *testing.T, name string, total int64) {
+	if len(r.events) < 2 || r.events[0].Type != ProgressStarted {
+		t.Fatalf("%s: except: started event, but got: %+v\n", name, r.events)
+	}
+	var last = r.events[len(r.events)-1]
+	if last.Type != ProgressCompleted || last.ConsumedBytes != total || last.TotalBytes != total {
+		t.Fatalf("%s: except: completed event of %d bytes, but got: %+v\n", name, total, last)
+	}
+}
+
+func TestPutObjectProgress(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var putAPI, _ = NewAPI(options)
+
+	var recorder = new(progressRecorder)
+	var opts = GetDefaultPutObjectOptions()
+	opts.Progress = recorder
+	if err := putAPI.PutObjectWithOptions("bucket", "object", strings.NewReader("hello world"), opts); err != nil {
+		t.Fatalf("PutObject: except: nil, but got: %s\n", err)
+	}
+	recorder.check(t, "PutObject", 11)
+}
+
+func TestGetObjectProgress(t *testing.T) {
+	var data = bytes.Repeat([]byte("a"), 10000)
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Write(data)
+	}))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var downloadAPI, _ = NewAPI(options)
+
+	var recorder = new(progressRecorder)
+	var opts = GetDefaultGetObjectOptions()
+	opts.Progress = recorder
+	var body, err = downloadAPI.GetObjectWithOptions("bucket", "object", opts)
+	if err != nil {
+		t.Fatalf("GetObject: except: nil, but got: %s\n", err)
+	}
+	ioutil.ReadAll(body)
+	body.Close()
+	recorder.check(t, "GetObject", 10000)
+
+	// the body closed before EOF
+	recorder = new(progressRecorder)
+	opts.Progress = recorder
+	if body, err = downloadAPI.GetObjectWithOptions("bucket", "object", opts); err != nil {
+		t.Fatalf("GetObject: except: nil, but got: %s\n", err)
+	}
+	body.Read(make([]byte, 100))
+	body.Close()
+	var last = recorder.events[len(recorder.events)-1]
+	if last.Type != ProgressFailed || last.Err != errBodyClosed {
+		t.Fatalf("GetObject: except: failed event of closed body, but got: %+v\n", last)
+	}
+	if count := recorder.count(ProgressFailed) + recorder.count(ProgressCompleted); count != 1 {
+		t.Fatalf("GetObject: except: %d finished event, but got: %d\n", 1, count)
+	}
+}
+
+func TestRetryProgress(t *testing.T) {
+	var attempts = 0
+	var retryAPI, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		attempts++
+		ioutil.ReadAll(req.Body)
+		if attempts < 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	defer closeServer()
+
+	var recorder = new(progressRecorder)
+	var ctx = WithProgressListener(context.Background(), recorder)
+	if err := retryAPI.PutObjectWithContext(ctx, "bucket", "object", strings.NewReader("hello world"), nil); err != nil {
+		t.Fatalf("PutObject: except: nil, but got: %s\n", err)
+	}
+	// the bytes sent by the failed attempt are taken back
+	recorder.check(t, "PutObject retry", 11)
+}
+
+func TestUploadProgress(t *testing.T) {
+	var server = &multipartTestServer{parts: make(map[int][]byte)}
+	var ts = httptest.NewServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var uploadAPI, _ = NewAPI(options)
+
+	var data = bytes.Repeat([]byte("0123456789"), MinPartSize/2)
+	var opts = GetDefaultUploadOptions()
+	opts.PartSize = MinPartSize
+	var recorder = new(progressRecorder)
+	opts.Progress = recorder
+	if _, err := uploadAPI.Upload("bucket", "object", bytes.NewReader(data), int64(len(data)), opts); err != nil {
+		t.Fatalf("Upload: except: nil, but got: %s\n", err)
+	}
+	recorder.check(t, "Upload", int64(len(data)))
+	if count := recorder.count(ProgressPartCompleted); count != 5 {
+		t.Fatalf("Upload: except: %d part events, but got: %d\n", 5, count)
+	}
+	if count := recorder.count(ProgressStarted); count != 1 {
+		t.Fatalf("Upload: except: %d started event, but got: %d\n", 1, count)
+	}
+}
+
+func TestUploadProgressFailed(t *testing.T) {
+	var server = &multipartTestServer{parts: make(map[int][]byte)}
+	server.failPart = 1
+	var ts = httptest.NewServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var uploadAPI, _ = NewAPI(options)
+
+	var recorder = new(progressRecorder)
+	var ctx = WithProgressListener(context.Background(), recorder)
+	var data = bytes.Repeat([]byte("a"), MinPartSize)
+	uploadAPI.UploadWithContext(ctx, "bucket", "object", bytes.NewReader(data), int64(len(data)), nil)
+	var last = recorder.events[len(recorder.events)-1]
+	if last.Type != ProgressFailed || last.Err == nil {
+		t.Fatalf("Upload: except: failed event, but got: %+v\n", last)
+	}
+}
@@ -0,0 +1,226 @@
+package oss
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// MinTrafficLimit the min value of x-oss-traffic-limit, 100KB/s in bit/s
+	MinTrafficLimit = 100 * 1024 * 8
+	// MaxTrafficLimit the max value of x-oss-traffic-limit, 100MB/s in bit/s
+	MaxTrafficLimit = 100 * 1024 * 1024 * 8
+)
+
+// RateLimiter limit the rate of events by a token bucket, it is safe for concurrent use
+type RateLimiter struct {
+	locker sync.Mutex
+	// the tokens added per second
+	rate float64
+	// the max tokens of bucket
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter create a limiter allows rate (positive) events per second, and burst events at once.
+// burst < 1 means the events of one second.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	var limiter = &RateLimiter{rate: rate, burst: float64(burst), last: time.Now()}
+	if limiter.burst < 1 {
+		limiter.burst = rate
+	}
+	if limiter.burst < 1 {
+		limiter.burst = 1
+	}
+	limiter.tokens = limiter.burst
+	return limiter
+}
+
+// Wait is like WaitN(ctx, 1)
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	return l.WaitN(ctx, 1)
+}
+
+// WaitN take n tokens, wait until they are available or ctx is done.
+// n may be larger than burst, the tokens are borrowed from the future.
+func (l *RateLimiter) WaitN(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	l.locker.Lock()
+	var now = time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.locker.Unlock()
+	return sleepWithContext(ctx, delay)
+}
+
+// Burst the max tokens taken at once without waiting
+func (l *RateLimiter) Burst() int {
+	return int(l.burst)
+}
+
+// newBandwidthLimiter create a limiter of bytes per second, nil if it is not limited
+func newBandwidthLimiter(bytesPerSecond int64) *RateLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return NewRateLimiter(float64(bytesPerSecond), 0)
+}
+
+// rateLimitReader throttle the reader by a limiter of bytes
+type rateLimitReader struct {
+	reader  io.Reader
+	limiter *RateLimiter
+	ctx     context.Context
+}
+
+func (r *rateLimitReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	// read no more than burst, so a large buffer does not borrow too many tokens
+	if burst := r.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	var n, err = r.reader.Read(p)
+	if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil && err == nil {
+		err = waitErr
+	}
+	return n, err
+}
+
+// rateLimitReadCloser throttle the response body
+type rateLimitReadCloser struct {
+	rateLimitReader
+	closer io.Closer
+}
+
+func (r *rateLimitReadCloser) Close() error {
+	return r.closer.Close()
+}
+
+// ErrRequestTimeout returned when the request has no progress within the timeout of API,
+// it is a net.Error of timeout, so the request is retried.
+var ErrRequestTimeout error = timeoutError{}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "oss: the request has no progress within the timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// idleTimer cancel the request when it waits the network longer than the timeout.
+// The time spent in reading the request body or by the caller between reads of response body is not counted,
+// so the transfers throttled by the bandwidth limiters never time out.
+type idleTimer struct {
+	timer   *time.Timer
+	timeout time.Duration
+	cancel  context.CancelFunc
+	locker  sync.Mutex
+	expired bool
+	// the response is received, the reads of request body never start the timer again
+	responded bool
+}
+
+func (t *idleTimer) expire() {
+	t.locker.Lock()
+	t.expired = true
+	t.locker.Unlock()
+	t.cancel()
+}
+
+// err replace the error caused by the expired timer with ErrRequestTimeout
+func (t *idleTimer) err(err error) error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	if t.expired && err != nil && err != io.EOF {
+		return ErrRequestTimeout
+	}
+	return err
+}
+
+func (t *idleTimer) start(request bool) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	if !t.expired && !(request && t.responded) {
+		t.timer.Reset(t.timeout)
+	}
+}
+
+func (t *idleTimer) pause(responded bool) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	t.timer.Stop()
+	t.responded = t.responded || responded
+}
+
+// idleRequestBody pause the idle timer while the transport reads the request body
+type idleRequestBody struct {
+	io.ReadCloser
+	timer *idleTimer
+}
+
+func (r *idleRequestBody) Read(p []byte) (int, error) {
+	r.timer.pause(false)
+	var n, err = r.ReadCloser.Read(p)
+	r.timer.start(true)
+	return n, err
+}
+
+// idleResponseBody start the idle timer while the caller reads the response body
+type idleResponseBody struct {
+	io.ReadCloser
+	timer *idleTimer
+}
+
+func (r *idleResponseBody) Read(p []byte) (int, error) {
+	r.timer.start(false)
+	var n, err = r.ReadCloser.Read(p)
+	r.timer.pause(true)
+	return n, r.timer.err(err)
+}
+
+func (r *idleResponseBody) Close() error {
+	var err = r.ReadCloser.Close()
+	r.timer.pause(true)
+	r.timer.cancel()
+	return err
+}
+
+// doRequest NOT public API
+// Send the request by api.client, it is canceled when it waits the network longer than api.timeout,
+// e.g.: connecting, sending the request, waiting the response headers and reading the response body.
+func (api *API) doRequest(req *http.Request) (*http.Response, error) {
+	if api.timeout <= 0 {
+		return api.client.Do(req)
+	}
+	var ctx, cancel = context.WithCancel(req.Context())
+	var timer = &idleTimer{timeout: api.timeout, cancel: cancel}
+	timer.timer = time.AfterFunc(api.timeout, timer.expire)
+	req = req.WithContext(ctx)
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &idleRequestBody{ReadCloser: req.Body, timer: timer}
+	}
+	var res, err = api.client.Do(req)
+	timer.pause(true)
+	if err != nil {
+		err = timer.err(err)
+		cancel()
+		return nil, err
+	}
+	res.Body = &idleResponseBody{ReadCloser: res.Body, timer: timer}
+	return res, nil
+}
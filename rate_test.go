@@ -0,0 +1,164 @@
+package oss
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	var limiter = NewRateLimiter(100, 1)
+	var start = time.Now()
+	for i := 0; i < 11; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait: except: nil, but got: %s\n", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("Wait: except: at least %s, but got: %s\n", 100*time.Millisecond, elapsed)
+	}
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.WaitN(ctx, 100); err != context.Canceled {
+		t.Fatalf("WaitN: except: %s, but got: %v\n", context.Canceled, err)
+	}
+}
+
+func TestQPSLimit(t *testing.T) {
+	var locker sync.Mutex
+	var count int
+	var api, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		locker.Lock()
+		count++
+		locker.Unlock()
+	})
+	defer closeServer()
+
+	api.SetQPS(50)
+	var start = time.Now()
+	for i := 0; i < 60; i++ {
+		if err := api.PutObject("bucket", "object", strings.NewReader("data"), nil); err != nil {
+			t.Fatalf("PutObject: except: nil, but got: %s\n", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond || count != 60 {
+		t.Fatalf("SetQPS: except: 60 requests in %s, but got: %d in %s\n", 200*time.Millisecond, count, elapsed)
+	}
+}
+
+func TestBandwidthLimit(t *testing.T) {
+	var data = bytes.Repeat([]byte("0123456789"), 7500)
+	var received []byte
+	var api, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == "PUT" {
+			received, _ = ioutil.ReadAll(req.Body)
+			return
+		}
+		w.Write(data)
+	})
+	defer closeServer()
+
+	api.SetBandwidth(50000, 50000)
+	var start = time.Now()
+	if err := api.PutObject("bucket", "object", bytes.NewReader(data), nil); err != nil {
+		t.Fatalf("PutObject: except: nil, but got: %s\n", err)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || !bytes.Equal(received, data) {
+		t.Fatalf("PutObject: except: %d bytes in %s, but got: %d in %s\n", len(data), 500*time.Millisecond,
+			len(received), elapsed)
+	}
+
+	start = time.Now()
+	var reader, err = api.GetObject("bucket", "object", nil, nil)
+	if err != nil {
+		t.Fatalf("GetObject: except: nil, but got: %s\n", err)
+	}
+	var got, _ = ioutil.ReadAll(reader)
+	reader.Close()
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || !bytes.Equal(got, data) {
+		t.Fatalf("GetObject: except: %d bytes in %s, but got: %d in %s\n", len(data), 500*time.Millisecond,
+			len(got), elapsed)
+	}
+}
+
+func TestTrafficLimit(t *testing.T) {
+	var headers []string
+	var api, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		headers = append(headers, req.Header.Get("x-oss-traffic-limit"))
+	})
+	defer closeServer()
+
+	var putOpts = GetDefaultPutObjectOptions()
+	putOpts.TrafficLimit = MinTrafficLimit
+	api.PutObject("bucket", "object", strings.NewReader("data"), putOpts.Headers())
+	var getOpts = GetDefaultGetObjectOptions()
+	getOpts.TrafficLimit = MaxTrafficLimit
+	if reader, err := api.GetObject("bucket", "object", getOpts.Headers(), getOpts.Params()); err == nil {
+		reader.Close()
+	}
+	var multi, _ = api.GetMultiPartUpload("bucket", "object", "upload-id")
+	multi.TrafficLimit = 1024 * 1024 * 8
+	multi.UploadPart(1, strings.NewReader("data"))
+	api.PutObject("bucket", "object", strings.NewReader("data"), nil)
+	// the values out of range are clamped
+	putOpts.TrafficLimit = 1024
+	api.PutObject("bucket", "object", strings.NewReader("data"), putOpts.Headers())
+	putOpts.TrafficLimit = MaxTrafficLimit + 1
+	api.PutObject("bucket", "object", strings.NewReader("data"), putOpts.Headers())
+
+	var excepts = []string{"819200", "838860800", "8388608", "", "819200", "838860800"}
+	if strings.Join(headers, ",") != strings.Join(excepts, ",") {
+		t.Fatalf("TrafficLimit: except: %v, but got: %v\n", excepts, headers)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	var data = bytes.Repeat([]byte("0123456789"), 5000)
+	var stall = make(chan struct{})
+	var api, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "/stall") {
+			<-stall
+			return
+		}
+		if req.Method == "PUT" {
+			ioutil.ReadAll(req.Body)
+			return
+		}
+		w.Write(data)
+	})
+	defer closeServer()
+	defer close(stall)
+
+	// the throttled transfers take about 1s, they are longer than the timeout
+	api.SetTimeout(300 * time.Millisecond)
+	api.SetBandwidth(25000, 25000)
+	var start = time.Now()
+	if err := api.PutObject("bucket", "object", bytes.NewReader(data), nil); err != nil {
+		t.Fatalf("PutObject: except: nil, but got: %s\n", err)
+	}
+	var reader, err = api.GetObject("bucket", "object", nil, nil)
+	if err != nil {
+		t.Fatalf("GetObject: except: nil, but got: %s\n", err)
+	}
+	var got, _ = ioutil.ReadAll(reader)
+	reader.Close()
+	if elapsed := time.Since(start); elapsed < 1500*time.Millisecond || !bytes.Equal(got, data) {
+		t.Fatalf("SetTimeout: except: %d bytes in %s, but got: %d in %s\n", len(data), 2*time.Second,
+			len(got), elapsed)
+	}
+
+	api.SetRetryTimes(1)
+	start = time.Now()
+	if _, err = api.GetObject("bucket", "stall", nil, nil); err != ErrRequestTimeout || !IsRetryable(err) {
+		t.Fatalf("GetObject: except: %s, but got: %v\n", ErrRequestTimeout, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("SetTimeout: except: %s, but got: %s\n", 300*time.Millisecond, elapsed)
+	}
+}
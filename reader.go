@@ -0,0 +1,224 @@
+package oss
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ReaderOptions defined the options of ObjectReader
+type ReaderOptions struct {
+	// the max size of each Range request of Read, a new Range request is sent when it is read to the end,
+	// nothing is prefetched. Default is 0, it means request to the end of object.
+	// A small RangeSize is better for random reads, e.g.: reading a zip file.
+	RangeSize int64
+	// the times to reconnect when the connection is dropped while reading, default is 3.
+	// The failed requests of reconnect are counted too.
+	MaxReconnects int
+}
+
+// GetDefaultReaderOptions get default reader options
+func GetDefaultReaderOptions() *ReaderOptions {
+	return &ReaderOptions{
+		MaxReconnects: 3,
+	}
+}
+
+// ObjectReader read the object by lazy Range requests, it implements io.ReadSeeker and io.ReaderAt.
+// All the requests are pinned to the ETag of object when it is opened,
+// if the object is overwritten the read failed with PreconditionFailed.
+type ObjectReader struct {
+	api    *API
+	ctx    context.Context
+	bucket string
+	object string
+	meta   *ObjectMeta
+	opts   *ReaderOptions
+
+	// the offset of Read and Seek
+	offset int64
+	// the body of current Range request and the offset it is read to
+	body       io.ReadCloser
+	bodyOffset int64
+}
+
+// NewObjectReader open the object to read, nil opts means GetDefaultReaderOptions().
+func (api *API) NewObjectReader(bucket, object string, opts *ReaderOptions) (*ObjectReader, error) {
+	return api.NewObjectReaderWithContext(context.Background(), bucket, object, opts)
+}
+
+// NewObjectReaderWithContext is like NewObjectReader, but with a context to cancel the requests.
+func (api *API) NewObjectReaderWithContext(ctx context.Context, bucket, object string,
+	opts *ReaderOptions) (*ObjectReader, error) {
+
+	if opts == nil {
+		opts = GetDefaultReaderOptions()
+	}
+	var meta, err = api.HeadObjectWithContext(ctx, bucket, object, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectReader{
+		api:    api,
+		ctx:    ctx,
+		bucket: bucket,
+		object: object,
+		meta:   meta,
+		opts:   opts,
+	}, nil
+}
+
+// Size get the size of object
+func (r *ObjectReader) Size() int64 {
+	return r.meta.Size
+}
+
+// Meta get the meta of object when it is opened
+func (r *ObjectReader) Meta() *ObjectMeta {
+	return r.meta
+}
+
+// closeBody close the body of current Range request
+func (r *ObjectReader) closeBody() {
+	if r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+}
+
+// openBody send a Range request from offset
+func (r *ObjectReader) openBody(offset int64) error {
+	r.closeBody()
+	var length = r.meta.Size - offset
+	if r.opts.RangeSize > 0 && r.opts.RangeSize < length {
+		length = r.opts.RangeSize
+	}
+	var body, err = r.api.getObjectRange(r.ctx, r.bucket, r.object, offset, length, r.meta.ETag)
+	if err != nil {
+		return err
+	}
+	r.body = body
+	r.bodyOffset = offset
+	return nil
+}
+
+// Read read the object from current offset, the dropped connection is reconnected.
+func (r *ObjectReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var reconnects int
+	for {
+		if r.offset >= r.meta.Size {
+			r.closeBody()
+			return 0, io.EOF
+		}
+		if r.body == nil || r.bodyOffset != r.offset {
+			if err := r.openBody(r.offset); err != nil {
+				// the failed request of a reconnect is counted against MaxReconnects
+				if reconnects == 0 || !IsRetryable(err) {
+					return 0, err
+				}
+				if err = r.reconnect(err, &reconnects); err != nil {
+					return 0, err
+				}
+				continue
+			}
+		}
+		var n, err = r.body.Read(p)
+		r.offset += int64(n)
+		r.bodyOffset += int64(n)
+		if err == nil {
+			return n, nil
+		}
+		// the range is read to the end, or the connection is dropped
+		r.closeBody()
+		if n > 0 {
+			return n, nil
+		}
+		if err != io.EOF {
+			if err = r.reconnect(err, &reconnects); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// reconnect count a reconnect after err, the err is returned if it can not reconnect any more
+func (r *ObjectReader) reconnect(err error, reconnects *int) error {
+	if r.ctx.Err() != nil {
+		return r.ctx.Err()
+	}
+	if *reconnects >= r.opts.MaxReconnects {
+		return err
+	}
+	*reconnects++
+	return nil
+}
+
+// Seek set the offset of next Read, the Range request is sent lazily by Read.
+func (r *ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.meta.Size
+	default:
+		return 0, errors.New("oss: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("oss: negative position")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// ReadAt read len(p) bytes from off with a new Range request, it never changes the offset of Read.
+// It is safe to call ReadAt at the same time.
+func (r *ObjectReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("oss: negative offset")
+	}
+	if off >= r.meta.Size {
+		return 0, io.EOF
+	}
+	var length = int64(len(p))
+	var eof error
+	if off+length > r.meta.Size {
+		length = r.meta.Size - off
+		eof = io.EOF
+	}
+
+	var read int64
+	var reconnects int
+	for read < length {
+		var body, err = r.api.getObjectRange(r.ctx, r.bucket, r.object, off+read, length-read, r.meta.ETag)
+		if err != nil {
+			// the failed request of a reconnect is counted against MaxReconnects
+			if reconnects == 0 || !IsRetryable(err) {
+				return int(read), err
+			}
+			if err = r.reconnect(err, &reconnects); err != nil {
+				return int(read), err
+			}
+			continue
+		}
+		var n int
+		n, err = io.ReadFull(body, p[read:length])
+		body.Close()
+		read += int64(n)
+		if err != nil && read < length {
+			if err = r.reconnect(err, &reconnects); err != nil {
+				return int(read), err
+			}
+		}
+	}
+	return int(read), eof
+}
+
+// Close close the current Range request
+func (r *ObjectReader) Close() error {
+	r.closeBody()
+	return nil
+}
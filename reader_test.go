@@ -0,0 +1,207 @@
+package oss
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestObjectReader(t *testing.T) {
+	var data = []byte(strings.Repeat("0123456789", 100))
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var readerAPI, _ = NewAPI(options)
+
+	var opts = GetDefaultReaderOptions()
+	opts.RangeSize = 64
+	var r, err = readerAPI.NewObjectReader("bucket", "object", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Size() != int64(len(data)) {
+		t.Fatalf("ObjectReader.Size: except: %d, but got: %d\n", len(data), r.Size())
+	}
+
+	var got []byte
+	if got, err = ioutil.ReadAll(r); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("ObjectReader.Read: the data is not equal to the object: %v\n", err)
+	}
+
+	r.Seek(-15, io.SeekEnd)
+	var buf = make([]byte, 5)
+	if _, err = io.ReadFull(r, buf); err != nil || string(buf) != "56789" {
+		t.Fatalf("ObjectReader.Seek: except: %s, but got: %s %v\n", "56789", buf, err)
+	}
+
+	var n int
+	if n, err = r.ReadAt(buf, 998); n != 2 || err != io.EOF || string(buf[:2]) != "89" {
+		t.Fatalf("ObjectReader.ReadAt: except: 89 EOF, but got: %s %v\n", buf[:n], err)
+	}
+	if n, err = r.ReadAt(buf, 101); err != nil || string(buf) != "12345" {
+		t.Fatalf("ObjectReader.ReadAt: except: %s, but got: %s %v\n", "12345", buf[:n], err)
+	}
+	// ReadAt never changes the offset of Read
+	if _, err = io.ReadFull(r, buf); err != nil || string(buf) != "01234" {
+		t.Fatalf("ObjectReader.Read: except: %s, but got: %s %v\n", "01234", buf, err)
+	}
+}
+
+func TestObjectReaderReconnect(t *testing.T) {
+	var data = []byte(strings.Repeat("0123456789", 100))
+	var count int
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		if r.Method != "GET" {
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+			return
+		}
+		if count++; count == 1 {
+			// the first 100 bytes are sent, and then the connection is closed
+			w.Header().Set("Content-Range", "bytes 0-"+strconv.Itoa(len(data)-1)+"/"+strconv.Itoa(len(data)))
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(data[:100])
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var readerAPI, _ = NewAPI(options)
+
+	var r, err = readerAPI.NewObjectReader("bucket", "object", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var got []byte
+	if got, err = ioutil.ReadAll(r); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("ObjectReader.Read: the data is not equal to the object: %v\n", err)
+	}
+	if count != 2 {
+		t.Fatalf("ObjectReader.Read: except: %d requests, but got: %d\n", 2, count)
+	}
+}
+
+func TestObjectReaderReconnectFailed(t *testing.T) {
+	var data = []byte(strings.Repeat("0123456789", 100))
+	var count int
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		if r.Method != "GET" {
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+			return
+		}
+		count++
+		switch {
+		case count == 1:
+			// the first 100 bytes are sent, and then the connection is closed
+			w.Header().Set("Content-Range", "bytes 0-"+strconv.Itoa(len(data)-1)+"/"+strconv.Itoa(len(data)))
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(data[:100])
+		case count <= 3:
+			// the requests of reconnect failed
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+		}
+	}))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	options.RetryPolicy = &DefaultRetryPolicy{Attempts: 1}
+	var readerAPI, _ = NewAPI(options)
+
+	// 1 dropped connection and 2 failed requests
+	var opts = GetDefaultReaderOptions()
+	opts.MaxReconnects = 2
+	var r, err = readerAPI.NewObjectReader("bucket", "object", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ioutil.ReadAll(r); err == nil {
+		t.Fatalf("ObjectReader.Read: except: ServiceUnavailable, but got: nil\n")
+	}
+	r.Close()
+
+	count = 0
+	opts.MaxReconnects = 3
+	if r, err = readerAPI.NewObjectReader("bucket", "object", opts); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var got []byte
+	if got, err = ioutil.ReadAll(r); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("ObjectReader.Read: the data is not equal to the object: %v\n", err)
+	}
+	if count != 4 {
+		t.Fatalf("ObjectReader.Read: except: %d requests, but got: %d\n", 4, count)
+	}
+}
+
+func TestObjectReaderZip(t *testing.T) {
+	var buf bytes.Buffer
+	var zw = zip.NewWriter(&buf)
+	var fw, _ = zw.Create("hello.txt")
+	fw.Write([]byte("hello world"))
+	zw.Close()
+
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(buf.Bytes()))
+	}))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var readerAPI, _ = NewAPI(options)
+
+	var r, err = readerAPI.NewObjectReader("bucket", "object.zip", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var zr *zip.Reader
+	if zr, err = zip.NewReader(r, r.Size()); err != nil {
+		t.Fatalf("zip.NewReader: except: nil, but got: %s\n", err)
+	}
+	var fr, _ = zr.File[0].Open()
+	var got, _ = ioutil.ReadAll(fr)
+	if string(got) != "hello world" {
+		t.Fatalf("zip: except: %s, but got: %s\n", "hello world", got)
+	}
+}
+
+func TestObjectReaderOverwritten(t *testing.T) {
+	var etag = `"etag1"`
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader("hello world"))
+	}))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var readerAPI, _ = NewAPI(options)
+
+	var r, err = readerAPI.NewObjectReader("bucket", "object", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag = `"etag2"`
+	if _, err = r.Read(make([]byte, 5)); err == nil {
+		t.Fatalf("ObjectReader.Read: except: PreconditionFailed, but got: nil\n")
+	}
+}
@@ -0,0 +1,129 @@
+package oss
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decide whether and when a failed request is sent again
+type RetryPolicy interface {
+	// MaxAttempts the max times one request is sent, including the first one
+	MaxAttempts() int
+	// ShouldRetry report whether the request should be sent again after the attempt failed.
+	// res is nil if no response is received, err is the transport error or an *Error.
+	ShouldRetry(attempt int, res *http.Response, err error) bool
+	// RetryDelay the time to wait before the next attempt
+	RetryDelay(attempt int, res *http.Response) time.Duration
+}
+
+// DefaultRetryCodes the OSS error codes retried by the DefaultRetryPolicy
+var DefaultRetryCodes = []string{"RequestTimeout", "SlowDown", "InternalError", "ServiceUnavailable"}
+
+// DefaultRetryPolicy retry the transport errors, the 5xx and 429 responses
+// and the retryable OSS error codes, with exponential backoff and jitter.
+type DefaultRetryPolicy struct {
+	// the max times one request is sent, including the first one
+	Attempts int
+	// backoff delay of the first retry, it doubles on every retry
+	BaseDelay time.Duration
+	// the max backoff delay
+	MaxDelay time.Duration
+	// OSS error codes should be retried whatever the status code is
+	RetryCodes []string
+}
+
+// GetDefaultRetryPolicy get default retry policy for OSS API
+func GetDefaultRetryPolicy() *DefaultRetryPolicy {
+	return &DefaultRetryPolicy{
+		Attempts:   5,
+		BaseDelay:  200 * time.Millisecond,
+		MaxDelay:   10 * time.Second,
+		RetryCodes: DefaultRetryCodes,
+	}
+}
+
+// MaxAttempts the max times one request is sent
+func (policy *DefaultRetryPolicy) MaxAttempts() int {
+	return policy.Attempts
+}
+
+// ShouldRetry retry on transport errors, 5xx, 429 and RetryCodes
+func (policy *DefaultRetryPolicy) ShouldRetry(attempt int, res *http.Response, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if res == nil {
+		return err != nil
+	}
+	if res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	var ossErr *Error
+	if errors.As(err, &ossErr) {
+		for _, code := range policy.RetryCodes {
+			if ossErr.Code == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RetryDelay use the Retry-After header if it exists,
+// otherwise an exponential backoff with jitter.
+func (policy *DefaultRetryPolicy) RetryDelay(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if delay, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return delay
+		}
+	}
+	var delay = policy.BaseDelay
+	for i := 0; i < attempt && delay < policy.MaxDelay; i++ {
+		delay = delay * 2
+	}
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// equal jitter: keep half of the delay and randomize the other half
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// parseRetryAfter parse Retry-After header, it is either seconds or a HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		var delay = time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// sleepWithContext wait for delay or return the ctx error when ctx is done
+func sleepWithContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	var timer = time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package oss
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newRetryTestAPI(t *testing.T, handler http.HandlerFunc) (*API, func()) {
+	var ts = httptest.NewServer(handler)
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var policy = GetDefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	options.RetryPolicy = policy
+	var api, err = NewAPI(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api, ts.Close
+}
+
+func TestRetryRewindBody(t *testing.T) {
+	var attempts = 0
+	var bodies []string
+	var api, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		attempts++
+		var body, _ = ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "<Error><Code>SlowDown</Code><Message>slow down</Message></Error>")
+			return
+		}
+	})
+	defer closeServer()
+
+	if err := api.PutObject("bucket", "object", bytes.NewReader([]byte("this is the body")), nil); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts: except: %d, but got: %d\n", 3, attempts)
+	}
+	for _, body := range bodies {
+		if body != "this is the body" {
+			t.Fatalf("body: except: %s, but got: %s\n", "this is the body", body)
+		}
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	var attempts = 0
+	var api, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "<Error><Code>AccessDenied</Code><Message>denied</Message></Error>")
+	})
+	defer closeServer()
+
+	if err := api.DeleteObject("bucket", "object"); err == nil {
+		t.Fatal("need fail, but success")
+	}
+	if attempts != 1 {
+		t.Fatalf("attempts: except: %d, but got: %d\n", 1, attempts)
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	var attempts = 0
+	var api, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "<Error><Code>InternalError</Code><Message>error</Message></Error>")
+	})
+	defer closeServer()
+
+	api.SetRetryTimes(3)
+	var err = api.DeleteObject("bucket", "object")
+	if ossErr, ok := err.(*Error); !ok || ossErr.Code != "InternalError" {
+		t.Fatalf("error: except: %s, but got: %v\n", "InternalError", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts: except: %d, but got: %d\n", 3, attempts)
+	}
+
+	// a body can not rewind is sent only once
+	attempts = 0
+	var body = bufio.NewReader(bytes.NewBufferString("this is the body"))
+	if _, err = api.httpRequest(&requestOptions{Method: "PUT", Bucket: "bucket", Body: body}); err == nil {
+		t.Fatal("need fail, but success")
+	}
+	if attempts != 1 {
+		t.Fatalf("attempts: except: %d, but got: %d\n", 1, attempts)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	var policy = GetDefaultRetryPolicy()
+	var res = &http.Response{Header: http.Header{}}
+	res.Header.Set("Retry-After", "3")
+	if got := policy.RetryDelay(0, res); got != 3*time.Second {
+		t.Fatalf("RetryDelay: except: %s, but got: %s\n", 3*time.Second, got)
+	}
+	for attempt := 0; attempt < 10; attempt++ {
+		var got = policy.RetryDelay(attempt, nil)
+		if got < 0 || got > policy.MaxDelay {
+			t.Fatalf("RetryDelay: %s out of range [0, %s]\n", got, policy.MaxDelay)
+		}
+	}
+	if got := policy.RetryDelay(6, nil); got < policy.MaxDelay/2 {
+		t.Fatalf("RetryDelay: except at least: %s, but got: %s\n", policy.MaxDelay/2, got)
+	}
+}
@@ -0,0 +1,261 @@
+package oss
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SignVersion defined the signature algorithm to sign requests
+type SignVersion string
+
+const (
+	// SignVersionV1 defined the HMAC-SHA1 signature, it is the default
+	SignVersionV1 SignVersion = "v1"
+	// SignVersionV4 defined the OSS4-HMAC-SHA256 signature
+	SignVersionV4 SignVersion = "v4"
+)
+
+// V4Algorithm defined the algorithm name of V4 signature
+const V4Algorithm = "OSS4-HMAC-SHA256"
+
+const (
+	v4Product         = "oss"
+	v4Request         = "aliyun_v4_request"
+	v4UnsignedPayload = "UNSIGNED-PAYLOAD"
+	v4DateFormat      = "20060102"
+	v4TimeFormat      = "20060102T150405Z"
+)
+
+var regionRegexp = regexp.MustCompile(`^oss-([a-z0-9-]+?)(-internal)?\.aliyuncs\.com$`)
+
+// getRegionFromHost get region from the OSS endpoint, e.g.: oss-cn-hangzhou.aliyuncs.com is cn-hangzhou
+func getRegionFromHost(host string) string {
+	var matched = regionRegexp.FindStringSubmatch(host)
+	if matched == nil {
+		return ""
+	}
+	return matched[1]
+}
+
+// v4Escape URI encode the string as RFC 3986, '/' is kept if keepSlash
+func v4Escape(str string, keepSlash bool) string {
+	var buf strings.Builder
+	for i := 0; i < len(str); i++ {
+		var c = str[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && keepSlash) {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+// getCanonicalURIV4 the URI is always /bucket/object whatever the host is
+func getCanonicalURIV4(bucket, object string) string {
+	if bucket == "" {
+		return "/"
+	}
+	return "/" + v4Escape(bucket, false) + "/" + v4Escape(object, true)
+}
+
+func getCanonicalQueryV4(query url.Values) string {
+	var keys = make([]string, 0, len(query))
+	var escaped = make(map[string]string, len(query))
+	for k, vs := range query {
+		var v string
+		if len(vs) > 0 {
+			v = vs[0]
+		}
+		var ek = v4Escape(k, false)
+		keys = append(keys, ek)
+		escaped[ek] = v4Escape(v, false)
+	}
+	sort.Strings(keys)
+	var pairs = make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k
+		if escaped[k] != "" {
+			pairs[i] = k + "=" + escaped[k]
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// getAdditionalHeadersV4 lower, sort and filter the additional headers that exists in headers
+func getAdditionalHeadersV4(headers map[string]string, additionalHeaders []string) []string {
+	var tmpHeaders = make(map[string]bool)
+	for k := range headers {
+		tmpHeaders[strings.ToLower(strings.TrimSpace(k))] = true
+	}
+	var result = make([]string, 0, len(additionalHeaders))
+	var seen = make(map[string]bool)
+	for _, k := range additionalHeaders {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if !tmpHeaders[k] || seen[k] || isDefaultSignedHeaderV4(k) {
+			continue
+		}
+		seen[k] = true
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// isDefaultSignedHeaderV4 these headers are always signed, so never be additional headers
+func isDefaultSignedHeaderV4(k string) bool {
+	return k == "content-type" || k == "content-md5" || strings.HasPrefix(k, SelfDefineHeaderPrefix)
+}
+
+// getCanonicalRequestV4 create the canonical request of V4 signature:
+//
+//	HTTPMethod + "\n" + CanonicalURI + "\n" + CanonicalQueryString + "\n" +
+//	CanonicalHeaders + "\n" + AdditionalHeaders + "\n" + HashedPayload
+func getCanonicalRequestV4(method, bucket, object string, query url.Values,
+	headers map[string]string, additionalHeaders []string) string {
+
+	var signed = make(map[string]bool)
+	for _, k := range additionalHeaders {
+		signed[k] = true
+	}
+	var tmpHeaders = make(map[string]string)
+	var keys = make([]string, 0)
+	for k, v := range headers {
+		var lower = strings.ToLower(strings.TrimSpace(k))
+		if !isDefaultSignedHeaderV4(lower) && !signed[lower] {
+			continue
+		}
+		if _, ok := tmpHeaders[lower]; !ok {
+			keys = append(keys, lower)
+		}
+		tmpHeaders[lower] = strings.TrimSpace(v)
+	}
+	sort.Strings(keys)
+	var canonicalHeaders strings.Builder
+	for _, k := range keys {
+		canonicalHeaders.WriteString(k)
+		canonicalHeaders.WriteString(":")
+		canonicalHeaders.WriteString(tmpHeaders[k])
+		canonicalHeaders.WriteString("\n")
+	}
+
+	var payload = safeGetElement("x-oss-content-sha256", headers)
+	if payload == "" {
+		payload = v4UnsignedPayload
+	}
+
+	return strings.Join([]string{
+		method,
+		getCanonicalURIV4(bucket, object),
+		getCanonicalQueryV4(query),
+		canonicalHeaders.String(),
+		strings.Join(additionalHeaders, ";"),
+		payload,
+	}, "\n")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	var h = hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// getScopeV4 the credential scope: <date>/<region>/oss/aliyun_v4_request
+func getScopeV4(date time.Time, region string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", date.UTC().Format(v4DateFormat), region, v4Product, v4Request)
+}
+
+// getSignatureV4 sign the canonical request with the derived signing key
+func getSignatureV4(secretAccessKey, region string, date time.Time, canonicalRequest string, debug bool) string {
+	var hash = sha256.Sum256([]byte(canonicalRequest))
+	var stringToSign = strings.Join([]string{
+		V4Algorithm,
+		date.UTC().Format(v4TimeFormat),
+		getScopeV4(date, region),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	if debug {
+		log.Printf("canonical_request:%s\n", canonicalRequest)
+		log.Printf("string_to_sign:%s\n", stringToSign)
+	}
+
+	return hex.EncodeToString(hmacSHA256(getSigningKeyV4(secretAccessKey, region, date), stringToSign))
+}
+
+// getSigningKeyV4 derive the signing key from secret, date, region and product
+func getSigningKeyV4(secretAccessKey, region string, date time.Time) []byte {
+	var key = hmacSHA256([]byte("aliyun_v4"+secretAccessKey), date.UTC().Format(v4DateFormat))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, v4Product)
+	return hmacSHA256(key, v4Request)
+}
+
+// parseURIQuery get the query values of request uri
+func parseURIQuery(uri string) url.Values {
+	var idx = strings.Index(uri, "?")
+	if idx < 0 {
+		return url.Values{}
+	}
+	var query, _ = url.ParseQuery(uri[idx+1:])
+	return query
+}
+
+// createSignV4 NOT public API
+// Create the V4 authorization of the request, the x-oss-date and
+// x-oss-content-sha256 headers are set into headers.
+//
+//   - creds: the credentials to sign
+//   - method: one of PUT, GET, DELETE, HEAD
+//   - bucket, object: the bucket and object of request
+//   - uri: the request uri with query string
+//   - headers: HTTP header
+//
+// Returns:
+//
+//	authorization string
+func (api *API) createSignV4(creds *Credentials, method, bucket, object, uri string,
+	headers map[string]string, now time.Time) string {
+
+	headers["x-oss-date"] = now.UTC().Format(v4TimeFormat)
+	headers["x-oss-content-sha256"] = v4UnsignedPayload
+	var additionalHeaders = getAdditionalHeadersV4(headers, api.additionalHeaders)
+	var canonicalRequest = getCanonicalRequestV4(method, bucket, object, parseURIQuery(uri), headers, additionalHeaders)
+	var signature = getSignatureV4(creds.SecretAccessKey, api.region, now, canonicalRequest, api.debug)
+
+	var authValue = fmt.Sprintf("%s Credential=%s/%s", V4Algorithm, creds.AccessID, getScopeV4(now, api.region))
+	if len(additionalHeaders) > 0 {
+		authValue = fmt.Sprintf("%s,AdditionalHeaders=%s", authValue, strings.Join(additionalHeaders, ";"))
+	}
+	return fmt.Sprintf("%s,Signature=%s", authValue, signature)
+}
+
+// presignV4 NOT public API
+// Set the V4 presign query params into params, include the x-oss-signature.
+func (api *API) presignV4(creds *Credentials, method, bucket, object string,
+	headers, params map[string]string, now time.Time, expires time.Duration) {
+
+	var additionalHeaders = getAdditionalHeadersV4(headers, api.additionalHeaders)
+	params["x-oss-signature-version"] = V4Algorithm
+	params["x-oss-credential"] = fmt.Sprintf("%s/%s", creds.AccessID, getScopeV4(now, api.region))
+	params["x-oss-date"] = now.UTC().Format(v4TimeFormat)
+	params["x-oss-expires"] = fmt.Sprintf("%d", int64(expires/time.Second))
+	if len(additionalHeaders) > 0 {
+		params["x-oss-additional-headers"] = strings.Join(additionalHeaders, ";")
+	}
+	if len(creds.StsToken) > 0 {
+		params["x-oss-security-token"] = creds.StsToken
+	}
+	var query = parseURIQuery(appendParam("", params))
+	var canonicalRequest = getCanonicalRequestV4(method, bucket, object, query, headers, additionalHeaders)
+	params["x-oss-signature"] = getSignatureV4(creds.SecretAccessKey, api.region, now, canonicalRequest, api.debug)
+}
@@ -0,0 +1,107 @@
+package oss
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetRegionFromHost(t *testing.T) {
+	var cases = map[string]string{
+		"oss-cn-hangzhou.aliyuncs.com":          "cn-hangzhou",
+		"oss-cn-beijing-internal.aliyuncs.com":  "cn-beijing",
+		"oss-accelerate.aliyuncs.com":           "accelerate",
+		"example.com":                           "",
+		"bucket.oss-cn-hangzhou.aliyuncs.com.x": "",
+	}
+	for host, except := range cases {
+		if got := getRegionFromHost(host); got != except {
+			t.Fatalf("getRegionFromHost(%s): except: %s, but got: %s\n", host, except, got)
+		}
+	}
+}
+
+func TestGetCanonicalRequestV4(t *testing.T) {
+	var headers = map[string]string{
+		"x-oss-head1":          "value",
+		"abc":                  "value",
+		"ZAbc":                 "value",
+		"Content-Type":         "text/plain",
+		"x-oss-content-sha256": "UNSIGNED-PAYLOAD",
+	}
+	var query = url.Values{}
+	query.Set("param1", "value1")
+	query.Set("+param1", "value3")
+	query.Set("|param1", "value4")
+	query.Set("param2", "")
+	var additional = getAdditionalHeadersV4(headers, []string{"ZAbc", "not-exists", "x-oss-head1"})
+	if strings.Join(additional, ";") != "zabc" {
+		t.Fatalf("getAdditionalHeadersV4: except: %s, but got: %v\n", "zabc", additional)
+	}
+	var got = getCanonicalRequestV4("PUT", "bucket", "1234+-/123/1.txt", query, headers, additional)
+	var except = "PUT\n" +
+		"/bucket/1234%2B-/123/1.txt\n" +
+		"%2Bparam1=value3&%7Cparam1=value4&param1=value1&param2\n" +
+		"content-type:text/plain\n" +
+		"x-oss-content-sha256:UNSIGNED-PAYLOAD\n" +
+		"x-oss-head1:value\n" +
+		"zabc:value\n" +
+		"\n" +
+		"zabc\n" +
+		"UNSIGNED-PAYLOAD"
+	if got != except {
+		t.Fatalf("getCanonicalRequestV4: except: %q, but got: %q\n", except, got)
+	}
+
+	var now = time.Date(2023, 12, 16, 16, 20, 57, 0, time.UTC)
+	var signature = getSignatureV4("sk", "cn-hangzhou", now, got, false)
+	if len(signature) != 64 || signature != getSignatureV4("sk", "cn-hangzhou", now, got, false) {
+		t.Fatalf("getSignatureV4: got unexcept signature: %s\n", signature)
+	}
+	if signature == getSignatureV4("sk", "cn-beijing", now, got, false) {
+		t.Fatalf("getSignatureV4: the region is not signed\n")
+	}
+}
+
+func TestSignV4Request(t *testing.T) {
+	var authorization, date string
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		authorization = req.Header.Get("Authorization")
+		date = req.Header.Get("x-oss-date")
+	}))
+	defer ts.Close()
+
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	options.AccessID = "ak"
+	options.SecretAccessKey = "sk"
+	options.SignVersion = SignVersionV4
+	if _, err := NewAPI(options); err == nil {
+		t.Fatal("need fail without region, but success")
+	}
+	options.Region = "cn-hangzhou"
+	var api, err = NewAPI(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = api.DeleteObject("bucket", "object"); err != nil {
+		t.Fatal(err)
+	}
+	var prefix = "OSS4-HMAC-SHA256 Credential=ak/" + date[:8] + "/cn-hangzhou/oss/aliyun_v4_request,Signature="
+	if !strings.HasPrefix(authorization, prefix) {
+		t.Fatalf("Authorization: except prefix: %s, but got: %s\n", prefix, authorization)
+	}
+
+	var signOptions = GetDefaultSignURLOptions()
+	signOptions.Bucket = "bucket"
+	signOptions.Object = "object"
+	var signURL, _ = url.Parse(api.SignURL(signOptions))
+	var query = signURL.Query()
+	if query.Get("x-oss-signature-version") != V4Algorithm || query.Get("x-oss-expires") != "60" ||
+		len(query.Get("x-oss-signature")) != 64 || query.Get("Signature") != "" {
+		t.Fatalf("SignURL: got unexcept url: %s\n", signURL)
+	}
+}
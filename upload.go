@@ -0,0 +1,249 @@
+package oss
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxPartCount the max number of parts of a multipart upload
+	MaxPartCount = 10000
+	// MinPartSize the min size of parts except the last one
+	MinPartSize = 100 * 1024
+	// abortTimeout the timeout of aborting the failed upload
+	abortTimeout = 30 * time.Second
+)
+
+// UploadOptions defined the options of parallel multipart upload
+type UploadOptions struct {
+	// the size of each part, it is enlarged to keep the parts within MaxPartCount,
+	// default is 8MB
+	PartSize int64
+	// the number of parts uploading at the same time, default is 3
+	Concurrency int
+	// the headers of initial multipart upload, e.g.: PutObjectOptions.Headers()
+	Headers map[string]string
+	// the checkpoint file to resume the upload, only for UploadFile.
+	// The progress is saved into it, when the upload failed, upload again with the same checkpoint
+	// only uploads the missing parts. It is removed after the upload completed.
+	Checkpoint string
+	// the bandwidth of each part in bit/s, it is clamped to [MinTrafficLimit, MaxTrafficLimit]
+	TrafficLimit int64
+	// listen the progress of upload, the listener of ctx is used if it is nil
+	Progress ProgressListener
+}
+
+// GetDefaultUploadOptions get default upload options
+func GetDefaultUploadOptions() *UploadOptions {
+	return &UploadOptions{
+		PartSize:    8 * 1024 * 1024,
+		Concurrency: 3,
+	}
+}
+
+// getPartSize get the part size for the object size, the parts are within MaxPartCount
+func getPartSize(size, partSize int64) int64 {
+	if partSize < MinPartSize {
+		partSize = MinPartSize
+	}
+	if size > partSize*MaxPartCount {
+		partSize = (size + MaxPartCount - 1) / MaxPartCount
+	}
+	return partSize
+}
+
+// UploadFile upload the file with parallel multipart upload, nil opts means GetDefaultUploadOptions().
+func (api *API) UploadFile(bucket, object, fileName string, opts *UploadOptions) (CompleteMultipartUploadResult, error) {
+	return api.UploadFileWithContext(context.Background(), bucket, object, fileName, opts)
+}
+
+// UploadFileWithContext is like UploadFile, but with a context to cancel the upload.
+func (api *API) UploadFileWithContext(ctx context.Context, bucket, object, fileName string,
+	opts *UploadOptions) (result CompleteMultipartUploadResult, err error) {
+
+	var fp *os.File
+	if fp, err = os.Open(fileName); err != nil {
+		return
+	}
+	defer fp.Close()
+
+	var stat os.FileInfo
+	if stat, err = fp.Stat(); err != nil {
+		return
+	}
+	if opts != nil && opts.Checkpoint != "" {
+		return api.resumeUpload(ctx, bucket, object, fp, stat, opts)
+	}
+	return api.UploadWithContext(ctx, bucket, object, fp, stat.Size(), opts)
+}
+
+// Upload upload size bytes of reader with parallel multipart upload,
+// every part is read by an io.SectionReader, so they can be read at the same time.
+// If any part or the completion failed, the multipart upload is aborted and the first error is returned.
+func (api *API) Upload(bucket, object string, reader io.ReaderAt, size int64,
+	opts *UploadOptions) (CompleteMultipartUploadResult, error) {
+	return api.UploadWithContext(context.Background(), bucket, object, reader, size, opts)
+}
+
+// UploadWithContext is like Upload, but with a context to cancel the upload.
+// When the ctx is canceled the multipart upload is aborted.
+func (api *API) UploadWithContext(ctx context.Context, bucket, object string, reader io.ReaderAt, size int64,
+	opts *UploadOptions) (result CompleteMultipartUploadResult, err error) {
+
+	if opts == nil {
+		opts = GetDefaultUploadOptions()
+	}
+
+	var progress = newProgressTracker(getProgressListener(ctx, opts.Progress), size)
+	progress.started()
+	defer func() {
+		progress.finish(err)
+	}()
+
+	var multi *MultipartUpload
+	if multi, err = api.NewMultipartUploadWithContext(ctx, bucket, object, copyMap(opts.Headers)); err != nil {
+		return
+	}
+	multi.TrafficLimit = opts.TrafficLimit
+
+	var parts []Part
+	if parts, err = multi.uploadParts(ctx, reader, size, getPartSize(size, opts.PartSize),
+		opts.Concurrency, nil, nil, progress); err != nil {
+		multi.abortDetached()
+		return
+	}
+	if err = multi.CompleteUploadWithContext(ctx, parts, &result); err != nil {
+		// the uploaded parts are never cleaned up if the upload is left behind
+		multi.abortDetached()
+	}
+	return
+}
+
+// abortDetached NOT public API
+// Abort the failed upload, the ctx of caller may be canceled already,
+// so it is aborted under a context of abortTimeout instead.
+func (multi *MultipartUpload) abortDetached() error {
+	var ctx, cancel = context.WithTimeout(context.Background(), abortTimeout)
+	defer cancel()
+	return multi.AbortUploadWithContext(ctx)
+}
+
+// uploadParts NOT public API
+// Upload the parts of reader with concurrency workers, the parts in done are skipped,
+// onPart is called after each part uploaded, and the progress of parts is added to progress.
+//
+// Returns:
+//
+//	all the parts sorted by part number, or the first error
+func (multi *MultipartUpload) uploadParts(ctx context.Context, reader io.ReaderAt, size, partSize int64,
+	concurrency int, done map[int]Part, onPart func(Part), progress *progressTracker) ([]Part, error) {
+
+	var partCount = int((size + partSize - 1) / partSize)
+	if partCount == 0 {
+		// an empty object still need one part
+		partCount = 1
+	}
+
+	var locker sync.Mutex
+	var parts = make([]Part, 0, partCount)
+	for _, part := range done {
+		parts = append(parts, part)
+	}
+	var skip = func(partNumber int) bool {
+		var _, ok = done[partNumber]
+		return ok
+	}
+	var err = runParallel(ctx, partCount, concurrency, skip, func(ctx context.Context, partNumber int) error {
+		var offset = int64(partNumber-1) * partSize
+		var length = partSize
+		if offset+length > size {
+			length = size - offset
+		}
+		var etag, err = multi.UploadPartWithContext(progress.partContext(ctx), partNumber,
+			io.NewSectionReader(reader, offset, length))
+		if err != nil {
+			return err
+		}
+		progress.partCompleted(partNumber)
+		if multi.api.debug {
+			log.Printf("PartNumber: %d, ETag: %s\n", partNumber, etag)
+		}
+		var part = Part{PartNumber: partNumber, ETag: etag}
+		locker.Lock()
+		parts = append(parts, part)
+		locker.Unlock()
+		if onPart != nil {
+			onPart(part)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+	return parts, nil
+}
+
+// runParallel NOT public API
+// Run fn for the parts numbered from 1 to count with concurrency workers, the parts skip returns true are skipped.
+// When fn failed, the others are canceled and the first error is returned.
+func runParallel(ctx context.Context, count, concurrency int, skip func(int) bool,
+	fn func(ctx context.Context, n int) error) error {
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var runCtx, cancel = context.WithCancel(ctx)
+	defer cancel()
+
+	var jobs = make(chan int)
+	var locker sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				if err := fn(runCtx, n); err != nil {
+					locker.Lock()
+					// the others fail with context.Canceled after the first error
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+					locker.Unlock()
+				}
+			}
+		}()
+	}
+
+	for n := 1; n <= count; n++ {
+		if skip != nil && skip(n) {
+			continue
+		}
+		select {
+		case jobs <- n:
+		case <-runCtx.Done():
+		}
+		if runCtx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return firstErr
+}
@@ -0,0 +1,171 @@
+package oss
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// multipartTestServer a fake OSS server only support multipart upload
+type multipartTestServer struct {
+	locker    sync.Mutex
+	parts     map[int][]byte
+	object    []byte
+	aborted   bool
+	failPart  int
+	running   int
+	maxRuning int
+	uploaded  int
+}
+
+func (server *multipartTestServer) handle(w http.ResponseWriter, r *http.Request) {
+	var query = r.URL.Query()
+	var data, _ = ioutil.ReadAll(r.Body)
+	server.locker.Lock()
+	defer server.locker.Unlock()
+	switch {
+	case r.Method == "POST" && query.Get("uploadId") == "":
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key>"+
+			"<UploadId>upload-id</UploadId></InitiateMultipartUploadResult>")
+	case r.Method == "PUT":
+		var partNumber, _ = strconv.Atoi(query.Get("partNumber"))
+		if partNumber == server.failPart {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, "<Error><Code>AccessDenied</Code><Message>part %d denied</Message></Error>", partNumber)
+			return
+		}
+		server.running++
+		if server.running > server.maxRuning {
+			server.maxRuning = server.running
+		}
+		server.locker.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		server.locker.Lock()
+		server.running--
+		server.uploaded++
+		server.parts[partNumber] = data
+		var sum = md5.Sum(data)
+		w.Header().Set("ETag", fmt.Sprintf("\"%s\"", hex.EncodeToString(sum[:])))
+	case r.Method == "GET":
+		var result ListPartsResult
+		for partNumber, part := range server.parts {
+			var sum = md5.Sum(part)
+			result.Parts = append(result.Parts, Part{PartNumber: partNumber,
+				ETag: fmt.Sprintf("\"%s\"", hex.EncodeToString(sum[:])), Size: len(part)})
+		}
+		data, _ = xml.Marshal(result)
+		w.Write(data)
+	case r.Method == "POST":
+		var complete struct {
+			Parts []Part `xml:"Part"`
+		}
+		xml.Unmarshal(data, &complete)
+		var buf bytes.Buffer
+		for _, part := range complete.Parts {
+			buf.Write(server.parts[part.PartNumber])
+		}
+		server.object = buf.Bytes()
+		fmt.Fprintf(w, "<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key>"+
+			"</CompleteMultipartUploadResult>")
+	case r.Method == "DELETE":
+		server.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestGetPartSize(t *testing.T) {
+	if size := getPartSize(1024, 1); size != MinPartSize {
+		t.Fatalf("getPartSize: except: %d, but got: %d\n", MinPartSize, size)
+	}
+	var size = getPartSize(MinPartSize*MaxPartCount*3+1, MinPartSize)
+	if (MinPartSize*MaxPartCount*3+1+size-1)/size > MaxPartCount {
+		t.Fatalf("getPartSize: the parts are more than %d with part size %d\n", MaxPartCount, size)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	var server = &multipartTestServer{parts: make(map[int][]byte)}
+	var ts = httptest.NewServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var uploadAPI, _ = NewAPI(options)
+
+	var data = bytes.Repeat([]byte("0123456789"), MinPartSize)
+	var opts = GetDefaultUploadOptions()
+	opts.PartSize = MinPartSize
+	opts.Concurrency = 4
+	var result, err = uploadAPI.Upload("bucket", "object", bytes.NewReader(data), int64(len(data)), opts)
+	if err != nil {
+		t.Fatalf("Upload: except: nil, but got: %s\n", err)
+	}
+	if result.Key != "object" {
+		t.Fatalf("Upload: except: %s, but got: %s\n", "object", result.Key)
+	}
+	if !bytes.Equal(server.object, data) {
+		t.Fatalf("Upload: the object is not equal to the data\n")
+	}
+	if server.uploaded != 10 {
+		t.Fatalf("Upload: except: %d parts, but got: %d\n", 10, server.uploaded)
+	}
+	if server.maxRuning < 2 || server.maxRuning > 4 {
+		t.Fatalf("Upload: except concurrency in [2, 4], but got: %d\n", server.maxRuning)
+	}
+}
+
+func TestUploadFailed(t *testing.T) {
+	var server = &multipartTestServer{parts: make(map[int][]byte)}
+	server.failPart = 3
+	var ts = httptest.NewServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var uploadAPI, _ = NewAPI(options)
+
+	var data = bytes.Repeat([]byte("0123456789"), MinPartSize)
+	var opts = GetDefaultUploadOptions()
+	opts.PartSize = MinPartSize
+	var _, err = uploadAPI.Upload("bucket", "object", bytes.NewReader(data), int64(len(data)), opts)
+	var ossErr, ok = err.(*Error)
+	if !ok || ossErr.Code != "AccessDenied" {
+		t.Fatalf("Upload: except: AccessDenied, but got: %v\n", err)
+	}
+	if !server.aborted {
+		t.Fatalf("Upload: the multipart upload is not aborted\n")
+	}
+}
+
+func TestUploadFile(t *testing.T) {
+	var server = &multipartTestServer{parts: make(map[int][]byte)}
+	var ts = httptest.NewServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var uploadAPI, _ = NewAPI(options)
+
+	var fp, err = ioutil.TempFile("", "oss-go-sdk-upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(fp.Name())
+	var data = bytes.Repeat([]byte("a"), MinPartSize*2+1)
+	fp.Write(data)
+	fp.Close()
+
+	if _, err = uploadAPI.UploadLargeFile("bucket", "object", fp.Name(), MinPartSize, nil); err != nil {
+		t.Fatalf("UploadLargeFile: except: nil, but got: %s\n", err)
+	}
+	if !bytes.Equal(server.object, data) || server.uploaded != 3 {
+		t.Fatalf("UploadLargeFile: the object is not equal to the data\n")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"net"
 	"net/url"
@@ -64,10 +65,31 @@ func isOSSHost(host string, isOSSHost bool) bool {
 func getAssign(secretAccessKey, method string, headers map[string]string,
 	resource string, result []string, debug bool) string {
 
-	var contentMd5, contentType, date, canonicalizedOSSHeaders string
 	if debug {
 		log.Printf("secretAccessKey: %s", secretAccessKey)
 	}
+	var stringToSign = getStringToSign(method, headers, resource, debug)
+	result = append(result, stringToSign)
+
+	var signResult = signStringV1(secretAccessKey, stringToSign)
+
+	if debug {
+		log.Printf("sign result: %s", signResult)
+	}
+
+	return signResult
+}
+
+// signStringV1 get the V1 signature of the string to sign
+func signStringV1(secretAccessKey, stringToSign string) string {
+	var h = hmac.New(sha1.New, []byte(secretAccessKey))
+	h.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// getStringToSign get the V1 string to sign of the method, headers and resource
+func getStringToSign(method string, headers map[string]string, resource string, debug bool) string {
+	var contentMd5, contentType, date, canonicalizedOSSHeaders string
 	contentMd5 = safeGetElement("Content-MD5", headers)
 	contentType = safeGetElement("Content-Type", headers)
 	date = safeGetElement("Date", headers)
@@ -86,22 +108,13 @@ func getAssign(secretAccessKey, method string, headers map[string]string,
 		}
 	}
 	var stringToSign = fmt.Sprintf("%s\n%s\n%s\n%s\n%s%s", method, contentMd5, contentType, date, canonicalizedOSSHeaders, canonicalizedResource)
-	result = append(result, stringToSign)
 
 	if debug {
 		log.Printf("method:%s\n content_md5:%s\n content_type:%s\n data:%s\n canonicalized_oss_headers:%s\n canonicalized_resource:%s\n", method, contentMd5, contentType, date, canonicalizedOSSHeaders, canonicalizedResource)
 		log.Printf("string_to_sign:%s\n \nlength of string_to_sign:%d\n", stringToSign, len(stringToSign))
 
 	}
-	var h = hmac.New(sha1.New, []byte(secretAccessKey))
-	h.Write([]byte(stringToSign))
-	var signResult = base64.StdEncoding.EncodeToString(h.Sum(nil))
-
-	if debug {
-		log.Printf("sign result: %s", signResult)
-	}
-
-	return signResult
+	return stringToSign
 }
 
 func safeGetElement(name string, container map[string]string) string {
@@ -143,7 +156,8 @@ func getResource(params map[string]string) string {
 		"acl", "uploadId", "uploads", "partNumber", "group", "link",
 		"delete", "website", "location", "objectInfo",
 		"response-expires", "response-content-disposition", "cors", "lifecycle",
-		"restore", "qos", "referer", "append", "position"}
+		"restore", "qos", "referer", "append", "position",
+		"security-token", "x-oss-process"}
 
 	sort.Strings(overrideResponseList)
 
@@ -231,3 +245,42 @@ func getBase64MD5WithReader(reader io.Reader) string {
 	io.Copy(h, reader)
 	return base64.StdEncoding.EncodeToString(h.Sum(nil))
 }
+
+// drainAndClose read the rest of body before close it,
+// so the keep-alive connection can be reused by the transport.
+func drainAndClose(body io.ReadCloser) {
+	io.Copy(ioutil.Discard, body)
+	body.Close()
+}
+
+// copyMap copy the map, so the origin one is never changed
+func copyMap(src map[string]string) map[string]string {
+	var dst = make(map[string]string, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+// getReaderLength get the unread length of reader if it can be known
+func getReaderLength(reader io.Reader) (int64, bool) {
+	switch r := reader.(type) {
+	case interface{ Len() int }:
+		// bytes.Buffer, bytes.Reader and strings.Reader
+		return int64(r.Len()), true
+	case io.Seeker:
+		var current, err = r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		var end int64
+		if end, err = r.Seek(0, io.SeekEnd); err != nil {
+			return 0, false
+		}
+		if _, err = r.Seek(current, io.SeekStart); err != nil {
+			return 0, false
+		}
+		return end - current, true
+	}
+	return 0, false
+}
@@ -0,0 +1,183 @@
+package oss
+
+import (
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The errors of VerifyRequest
+var (
+	// ErrSignatureMissing the request has neither Authorization header nor signature params, it is anonymous
+	ErrSignatureMissing = errors.New("oss: the request is not signed")
+	// ErrSignatureNotSupported the request is not signed by the V1 signature
+	ErrSignatureNotSupported = errors.New("oss: only the V1 signature can be verified")
+	// ErrSignatureMalformed the Authorization, Date or Expires of request is invalid
+	ErrSignatureMalformed = errors.New("oss: the signature of request is malformed")
+	// ErrSignatureDoesNotMatch the signature is not signed by the secret access key
+	ErrSignatureDoesNotMatch = errors.New("oss: the signature does not match")
+	// ErrSignatureExpired the presigned url has expired
+	ErrSignatureExpired = errors.New("oss: the presigned url has expired")
+	// ErrRequestTimeTooSkewed the Date header is too far from the current time
+	ErrRequestTimeTooSkewed = errors.New("oss: the difference between the request time and the current time is too large")
+	// ErrInvalidSecurityToken the STS token of request is not the token of access id
+	ErrInvalidSecurityToken = errors.New("oss: the security token is invalid")
+	// ErrAccessIDNotFound should be returned by SecretLookup when the access id does not exist
+	ErrAccessIDNotFound = errors.New("oss: the access id does not exist")
+)
+
+// SecretLookup get the secret access key and the STS token of access id,
+// the token is empty if the access id is not a temporary credential.
+type SecretLookup func(accessID string) (secretAccessKey, stsToken string, err error)
+
+// VerifyOptions defined the options of VerifyRequest
+type VerifyOptions struct {
+	// the host of endpoint, e.g.: oss-cn-hangzhou.aliyuncs.com.
+	// The request to bucket.Host is virtual-hosted style, others are path style: /bucket/object
+	Host string
+	// the max difference between the Date header and the current time, default is 15 minutes
+	MaxClockSkew time.Duration
+}
+
+// GetDefaultVerifyOptions get default verify options
+func GetDefaultVerifyOptions() *VerifyOptions {
+	return &VerifyOptions{
+		MaxClockSkew: 15 * time.Minute,
+	}
+}
+
+// VerifyResult defined the signer and the resource of a verified request
+type VerifyResult struct {
+	AccessID string
+	Bucket   string
+	Object   string
+	// the request is signed by the params of presigned url instead of Authorization header
+	Presigned bool
+	// the string to sign calculated from the request, it is set with ErrSignatureDoesNotMatch
+	StringToSign string
+}
+
+// VerifyRequest verify the V1 signature of the Authorization header, or the presigned url created by
+// Presign and SignURL. The Date header or the expire time of url, and the STS token are checked too.
+// nil opts means GetDefaultVerifyOptions().
+// It returns ErrSignatureMissing if the request is anonymous, or the error of lookup,
+// the result is returned with ErrSignatureDoesNotMatch too.
+func VerifyRequest(req *http.Request, lookup SecretLookup, opts *VerifyOptions) (*VerifyResult, error) {
+	if opts == nil {
+		opts = GetDefaultVerifyOptions()
+	}
+	var maxClockSkew = opts.MaxClockSkew
+	if maxClockSkew <= 0 {
+		maxClockSkew = 15 * time.Minute
+	}
+
+	var result = new(VerifyResult)
+	var err error
+	if result.Bucket, result.Object, err = getRequestResource(req, opts.Host); err != nil {
+		return nil, err
+	}
+
+	var query = req.URL.Query()
+	var headers = make(map[string]string)
+	for k, v := range req.Header {
+		if len(v) > 0 {
+			headers[k] = v[0]
+		}
+	}
+	var params = make(map[string]string)
+	for k, v := range query {
+		if len(v) > 0 {
+			params[k] = v[0]
+		}
+	}
+
+	var signature, token string
+	var auth = req.Header.Get("Authorization")
+	switch {
+	case auth != "":
+		if !strings.HasPrefix(auth, PROVIDER+" ") {
+			return nil, ErrSignatureNotSupported
+		}
+		var parts = strings.SplitN(strings.TrimPrefix(auth, PROVIDER+" "), ":", 2)
+		if len(parts) != 2 {
+			return nil, ErrSignatureMalformed
+		}
+		result.AccessID, signature = parts[0], parts[1]
+		var date time.Time
+		if date, err = http.ParseTime(req.Header.Get("Date")); err != nil {
+			return nil, ErrSignatureMalformed
+		}
+		if skew := time.Since(date); skew > maxClockSkew || -skew > maxClockSkew {
+			return nil, ErrRequestTimeTooSkewed
+		}
+		token = req.Header.Get("x-oss-security-token")
+	case query.Get("Signature") != "":
+		result.Presigned = true
+		result.AccessID = query.Get("OSSAccessKeyId")
+		signature = query.Get("Signature")
+		var expires int64
+		if expires, err = strconv.ParseInt(query.Get("Expires"), 10, 64); err != nil {
+			return nil, ErrSignatureMalformed
+		}
+		if time.Now().Unix() > expires {
+			return nil, ErrSignatureExpired
+		}
+		token = query.Get("security-token")
+		// the Date of string to sign is the expire unix timestamp
+		headers["Date"] = query.Get("Expires")
+	default:
+		return nil, ErrSignatureMissing
+	}
+
+	var secretAccessKey, stsToken string
+	if secretAccessKey, stsToken, err = lookup(result.AccessID); err != nil {
+		return nil, err
+	}
+	if token != stsToken {
+		return nil, ErrInvalidSecurityToken
+	}
+
+	var resource = fmt.Sprintf("/%s/%s%s", result.Bucket, result.Object, getResource(params))
+	if result.Bucket == "" {
+		resource = "/" + getResource(params)
+	}
+	var stringToSign = getStringToSign(req.Method, headers, resource, false)
+	if !hmac.Equal([]byte(signature), []byte(signStringV1(secretAccessKey, stringToSign))) {
+		result.StringToSign = stringToSign
+		return result, ErrSignatureDoesNotMatch
+	}
+	return result, nil
+}
+
+// getRequestResource get the bucket and object of request, host is the endpoint of virtual-hosted style
+func getRequestResource(req *http.Request, host string) (bucket, object string, err error) {
+	var reqHost = req.Host
+	if reqHost == "" {
+		reqHost = req.URL.Host
+	}
+	if h, _, err := net.SplitHostPort(reqHost); err == nil {
+		reqHost = h
+	}
+	var path = strings.TrimPrefix(req.URL.EscapedPath(), "/")
+	if host != "" && strings.HasSuffix(reqHost, "."+host) {
+		bucket = strings.TrimSuffix(reqHost, "."+host)
+	} else {
+		var parts = strings.SplitN(path, "/", 2)
+		bucket, path = parts[0], ""
+		if len(parts) == 2 {
+			path = parts[1]
+		}
+	}
+	// the object is escaped as query string by the requests of API, and by v4Escape in presigned url,
+	// QueryUnescape decodes both of them
+	if object, err = url.QueryUnescape(path); err != nil {
+		return "", "", ErrSignatureMalformed
+	}
+	return bucket, object, nil
+}
@@ -0,0 +1,164 @@
+package oss
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newVerifyTestLookup(stsToken string) SecretLookup {
+	return func(accessID string) (string, string, error) {
+		if accessID != "id" {
+			return "", "", ErrAccessIDNotFound
+		}
+		return "secret", stsToken, nil
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	var errs []error
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var result, err = VerifyRequest(req, newVerifyTestLookup(""), nil)
+		if err == nil && (result.Bucket != "bucket" || result.Object != "dir/a b+c.txt" || result.AccessID != "id") {
+			t.Errorf("VerifyRequest: got unexcept result: %+v\n", result)
+		}
+		errs = append(errs, err)
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	options.AccessID, options.SecretAccessKey = "id", "secret"
+	var api, _ = NewAPI(options)
+
+	var opts = GetDefaultPutObjectOptions()
+	opts.ContentType = "text/plain"
+	opts.UserMeta["author"] = "verify"
+	api.PutObject("bucket", "dir/a b+c.txt", strings.NewReader("data"), opts.Headers())
+	api.PutObjectACL("bucket", "dir/a b+c.txt", string(ACLPublicRead))
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("VerifyRequest %d: except: nil, but got: %s\n", i, err)
+		}
+	}
+
+	api.SetCredentialsProvider(NewStaticCredentialsProvider("id", "wrong", ""))
+	api.PutObject("bucket", "dir/a b+c.txt", strings.NewReader("data"), nil)
+	api.SetCredentialsProvider(NewStaticCredentialsProvider("unknown", "secret", ""))
+	api.PutObject("bucket", "dir/a b+c.txt", strings.NewReader("data"), nil)
+	api.SetCredentialsProvider(NewStaticCredentialsProvider("id", "secret", "token"))
+	api.PutObject("bucket", "dir/a b+c.txt", strings.NewReader("data"), nil)
+	var excepts = []error{ErrSignatureDoesNotMatch, ErrAccessIDNotFound, ErrInvalidSecurityToken}
+	for i, except := range excepts {
+		if err := errs[2+i]; err != except {
+			t.Fatalf("VerifyRequest: except: %s, but got: %v\n", except, err)
+		}
+	}
+}
+
+func TestVerifyRequestHeader(t *testing.T) {
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(mockServer.URL)
+	options.AccessID, options.SecretAccessKey = "id", "secret"
+	var api, _ = NewAPI(options)
+	var err error
+	var creds, _ = api.credentials.GetCredentials(context.Background())
+	var sign = func(req *http.Request, resource string) {
+		var headers = map[string]string{"Date": req.Header.Get("Date"), "x-oss-meta-a": "1"}
+		req.Header.Set("x-oss-meta-a", "1")
+		req.Header.Set("Authorization", api.createSignForNormalAuth(creds, req.Method, headers, resource))
+	}
+
+	var req = httptest.NewRequest("GET", "http://bucket.oss-cn-hangzhou.aliyuncs.com/object?acl", nil)
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	sign(req, "/bucket/object?acl")
+	var opts = GetDefaultVerifyOptions()
+	opts.Host = "oss-cn-hangzhou.aliyuncs.com"
+	var result *VerifyResult
+	if result, err = VerifyRequest(req, newVerifyTestLookup(""), opts); err != nil {
+		t.Fatalf("VerifyRequest: except: nil, but got: %s\n", err)
+	}
+	if result.Bucket != "bucket" || result.Object != "object" || result.Presigned {
+		t.Fatalf("VerifyRequest: got unexcept result: %+v\n", result)
+	}
+
+	req.Header.Set("x-oss-meta-a", "2")
+	if result, err = VerifyRequest(req, newVerifyTestLookup(""), opts); err != ErrSignatureDoesNotMatch ||
+		!strings.Contains(result.StringToSign, "x-oss-meta-a:2\n/bucket/object?acl") {
+		t.Fatalf("VerifyRequest: except: %s, but got: %v %+v\n", ErrSignatureDoesNotMatch, err, result)
+	}
+
+	req = httptest.NewRequest("GET", "http://bucket.oss-cn-hangzhou.aliyuncs.com/object", nil)
+	req.Header.Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	sign(req, "/bucket/object")
+	if _, err = VerifyRequest(req, newVerifyTestLookup(""), opts); err != ErrRequestTimeTooSkewed {
+		t.Fatalf("VerifyRequest: except: %s, but got: %v\n", ErrRequestTimeTooSkewed, err)
+	}
+
+	req = httptest.NewRequest("GET", "http://bucket.oss-cn-hangzhou.aliyuncs.com/object", nil)
+	if _, err = VerifyRequest(req, newVerifyTestLookup(""), opts); err != ErrSignatureMissing {
+		t.Fatalf("VerifyRequest: except: %s, but got: %v\n", ErrSignatureMissing, err)
+	}
+	req.Header.Set("Authorization", "OSS4-HMAC-SHA256 Credential=verify-id")
+	if _, err = VerifyRequest(req, newVerifyTestLookup(""), opts); err != ErrSignatureNotSupported {
+		t.Fatalf("VerifyRequest: except: %s, but got: %v\n", ErrSignatureNotSupported, err)
+	}
+}
+
+func TestVerifyPresign(t *testing.T) {
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(mockServer.URL)
+	options.AccessID, options.SecretAccessKey, options.StsToken = "id", "secret", "token"
+	var api, _ = NewAPI(options)
+	var opts = GetDefaultVerifyOptions()
+
+	var presignOpts = GetDefaultPresignOptions()
+	presignOpts.Method = "PUT"
+	presignOpts.ContentType = "text/plain"
+	presignOpts.Headers = map[string]string{"x-oss-meta-author": "verify"}
+	var signURL, err = api.Presign("bucket", "dir/a b+c.txt", presignOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req = httptest.NewRequest("PUT", signURL, strings.NewReader("data"))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("x-oss-meta-author", "verify")
+	var result *VerifyResult
+	if result, err = VerifyRequest(req, newVerifyTestLookup("token"), opts); err != nil {
+		t.Fatalf("VerifyRequest: except: nil, but got: %s\n", err)
+	}
+	if result.Bucket != "bucket" || result.Object != "dir/a b+c.txt" || !result.Presigned {
+		t.Fatalf("VerifyRequest: got unexcept result: %+v\n", result)
+	}
+
+	req.Header.Set("Content-Type", "text/html")
+	if _, err = VerifyRequest(req, newVerifyTestLookup("token"), opts); err != ErrSignatureDoesNotMatch {
+		t.Fatalf("VerifyRequest: except: %s, but got: %v\n", ErrSignatureDoesNotMatch, err)
+	}
+	if _, err = VerifyRequest(req, newVerifyTestLookup("other"), opts); err != ErrInvalidSecurityToken {
+		t.Fatalf("VerifyRequest: except: %s, but got: %v\n", ErrInvalidSecurityToken, err)
+	}
+
+	// the url signed by SignURL without STS token
+	options.StsToken = ""
+	api, _ = NewAPI(options)
+	var signOpts = GetDefaultSignURLOptions()
+	signOpts.Bucket, signOpts.Object = "bucket", "object"
+	req = httptest.NewRequest("GET", api.SignURL(signOpts), nil)
+	if result, err = VerifyRequest(req, newVerifyTestLookup(""), nil); err != nil || result.Object != "object" {
+		t.Fatalf("VerifyRequest: except: nil, but got: %v %+v\n", err, result)
+	}
+
+	var creds, _ = api.credentials.GetCredentials(context.Background())
+	var params = make(map[string]string)
+	api.presign(creds, "GET", "bucket", "object", make(map[string]string), params, time.Now().Add(-time.Hour), time.Minute)
+	req = httptest.NewRequest("GET", appendParam(api.getObjectURL("bucket", "object"), params), nil)
+	if _, err = VerifyRequest(req, newVerifyTestLookup(""), nil); err != ErrSignatureExpired {
+		t.Fatalf("VerifyRequest: except: %s, but got: %v\n", ErrSignatureExpired, err)
+	}
+}
@@ -0,0 +1,227 @@
+package oss
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrWriterClosed returned when write to a closed ObjectWriter
+var ErrWriterClosed = errors.New("oss: write to closed object writer")
+
+// ObjectWriter upload the data written into it as an object, the size need not be known ahead.
+// The data is buffered up to the part size, the full parts are uploaded in the background by multipart upload.
+// If the data is less than one part, it is uploaded by PutObject on Close.
+// At most (Concurrency + 1) * PartSize bytes are buffered in memory,
+// and the object is limited to MaxPartCount parts.
+type ObjectWriter struct {
+	api    *API
+	ctx    context.Context
+	cancel context.CancelFunc
+	bucket string
+	object string
+	opts   *UploadOptions
+
+	partSize   int64
+	buf        *bytes.Buffer
+	multi      *MultipartUpload
+	partNumber int
+	sem        chan struct{}
+	wg         sync.WaitGroup
+	closed     bool
+
+	progress *progressTracker
+
+	locker sync.Mutex
+	parts  []Part
+	err    error
+}
+
+// NewObjectWriter create an ObjectWriter, nil opts means GetDefaultUploadOptions().
+// The writer must be closed by Close to complete the upload, or by CloseWithError to abort it.
+func (api *API) NewObjectWriter(bucket, object string, opts *UploadOptions) *ObjectWriter {
+	return api.NewObjectWriterWithContext(context.Background(), bucket, object, opts)
+}
+
+// NewObjectWriterWithContext is like NewObjectWriter, but with a context to cancel the upload.
+func (api *API) NewObjectWriterWithContext(ctx context.Context, bucket, object string, opts *UploadOptions) *ObjectWriter {
+	if opts == nil {
+		opts = GetDefaultUploadOptions()
+	}
+	var concurrency = opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var partSize = getPartSize(0, opts.PartSize)
+	var progress = newProgressTracker(getProgressListener(ctx, opts.Progress), -1)
+	progress.started()
+	ctx, cancel := context.WithCancel(ctx)
+	return &ObjectWriter{
+		api:      api,
+		ctx:      ctx,
+		cancel:   cancel,
+		bucket:   bucket,
+		object:   object,
+		opts:     opts,
+		partSize: partSize,
+		buf:      bytes.NewBuffer(make([]byte, 0, partSize)),
+		sem:      make(chan struct{}, concurrency),
+		progress: progress,
+	}
+}
+
+func (w *ObjectWriter) getErr() error {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	return w.err
+}
+
+func (w *ObjectWriter) setErr(err error) {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// Write buffer the data, and upload it when a part is full.
+// The error of background uploading is returned by the next Write.
+func (w *ObjectWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	var written int
+	for len(p) > 0 {
+		if err := w.getErr(); err != nil {
+			return written, err
+		}
+		var n = int(w.partSize) - w.buf.Len()
+		if n > len(p) {
+			n = len(p)
+		}
+		w.buf.Write(p[:n])
+		written += n
+		p = p[n:]
+		if int64(w.buf.Len()) == w.partSize {
+			if err := w.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// flush upload the buffer as a part in the background
+func (w *ObjectWriter) flush() error {
+	if w.multi == nil {
+		var multi, err = w.api.NewMultipartUploadWithContext(w.ctx, w.bucket, w.object, copyMap(w.opts.Headers))
+		if err != nil {
+			w.setErr(err)
+			return err
+		}
+		multi.TrafficLimit = w.opts.TrafficLimit
+		w.multi = multi
+	}
+	if w.partNumber >= MaxPartCount {
+		var err = fmt.Errorf("oss: object writer is limited to %d parts", MaxPartCount)
+		w.setErr(err)
+		return err
+	}
+	w.partNumber++
+
+	// wait for a free worker, so the buffered parts are limited
+	select {
+	case w.sem <- struct{}{}:
+	case <-w.ctx.Done():
+		w.setErr(w.ctx.Err())
+		return w.ctx.Err()
+	}
+	var data = w.buf.Bytes()
+	var partNumber = w.partNumber
+	w.buf = bytes.NewBuffer(make([]byte, 0, w.partSize))
+
+	w.wg.Add(1)
+	go func() {
+		defer func() {
+			<-w.sem
+			w.wg.Done()
+		}()
+		if w.getErr() != nil {
+			return
+		}
+		var etag, err = w.multi.UploadPartWithContext(w.progress.partContext(w.ctx), partNumber, bytes.NewReader(data))
+		if err != nil {
+			w.setErr(err)
+			return
+		}
+		w.progress.partCompleted(partNumber)
+		w.locker.Lock()
+		w.parts = append(w.parts, Part{PartNumber: partNumber, ETag: etag})
+		w.locker.Unlock()
+	}()
+	return nil
+}
+
+// Close upload the rest data and complete the upload.
+// If any part or the completion failed, the multipart upload is aborted and the first error is returned.
+func (w *ObjectWriter) Close() (err error) {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.closed = true
+	defer func() {
+		w.progress.finish(err)
+		w.cancel()
+	}()
+
+	if w.multi == nil {
+		if err := w.getErr(); err != nil {
+			return err
+		}
+		var headers = copyMap(w.opts.Headers)
+		setTrafficLimit(headers, w.opts.TrafficLimit)
+		return w.api.PutObjectWithContext(w.progress.partContext(w.ctx), w.bucket, w.object, bytes.NewReader(w.buf.Bytes()),
+			headers)
+	}
+
+	if w.buf.Len() > 0 && w.getErr() == nil {
+		w.flush()
+	}
+	w.wg.Wait()
+	if err := w.getErr(); err != nil {
+		w.multi.abortDetached()
+		return err
+	}
+	sort.Slice(w.parts, func(i, j int) bool {
+		return w.parts[i].PartNumber < w.parts[j].PartNumber
+	})
+	var result CompleteMultipartUploadResult
+	if err := w.multi.CompleteUploadWithContext(w.ctx, w.parts, &result); err != nil {
+		w.multi.abortDetached()
+		return err
+	}
+	return nil
+}
+
+// CloseWithError abort the upload because of err, e.g.: the source is broken,
+// the uploading parts are stopped and the uploaded parts are deleted.
+func (w *ObjectWriter) CloseWithError(err error) error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.closed = true
+	if err == nil {
+		err = ErrWriterClosed
+	}
+	w.setErr(err)
+	w.cancel()
+	w.wg.Wait()
+	w.progress.failed(err)
+	if w.multi == nil {
+		return nil
+	}
+	return w.multi.abortDetached()
+}
@@ -0,0 +1,97 @@
+package oss
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestObjectWriter(t *testing.T) {
+	var server = &multipartTestServer{parts: make(map[int][]byte)}
+	var ts = httptest.NewServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var writerAPI, _ = NewAPI(options)
+
+	var data = bytes.Repeat([]byte("0123456789"), MinPartSize/4)
+	var opts = GetDefaultUploadOptions()
+	opts.PartSize = MinPartSize
+	var w = writerAPI.NewObjectWriter("bucket", "object", opts)
+	// write in small pieces like a pipe
+	var n, err = io.CopyBuffer(w, bytes.NewReader(data), make([]byte, 1000))
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("ObjectWriter.Write: except: %d, but got: %d %v\n", len(data), n, err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("ObjectWriter.Close: except: nil, but got: %s\n", err)
+	}
+	if server.uploaded != 3 || !bytes.Equal(server.object, data) {
+		t.Fatalf("ObjectWriter: the object is not equal to the data\n")
+	}
+	if _, err = w.Write([]byte("a")); err != ErrWriterClosed {
+		t.Fatalf("ObjectWriter.Write: except: %s, but got: %v\n", ErrWriterClosed, err)
+	}
+}
+
+func TestObjectWriterSmall(t *testing.T) {
+	var body []byte
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var writerAPI, _ = NewAPI(options)
+
+	var w = writerAPI.NewObjectWriter("bucket", "object", nil)
+	w.Write([]byte("hello "))
+	w.Write([]byte("world"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("ObjectWriter.Close: except: nil, but got: %s\n", err)
+	}
+	if string(body) != "hello world" {
+		t.Fatalf("ObjectWriter: except: %s, but got: %s\n", "hello world", body)
+	}
+}
+
+func TestObjectWriterAbort(t *testing.T) {
+	var server = &multipartTestServer{parts: make(map[int][]byte)}
+	server.failPart = 2
+	var ts = httptest.NewServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+	var options = GetDefaultAPIOptioins()
+	options.Host, options.Port = getHostFromURL(ts.URL)
+	var writerAPI, _ = NewAPI(options)
+
+	var opts = GetDefaultUploadOptions()
+	opts.PartSize = MinPartSize
+	opts.Concurrency = 1
+	var w = writerAPI.NewObjectWriter("bucket", "object", opts)
+	var data = bytes.Repeat([]byte("a"), MinPartSize)
+	var err error
+	for i := 0; i < 5 && err == nil; i++ {
+		_, err = w.Write(data)
+	}
+	if err = w.Close(); err == nil {
+		t.Fatalf("ObjectWriter.Close: except: AccessDenied, but got: nil\n")
+	}
+	if !server.aborted {
+		t.Fatalf("ObjectWriter.Close: the multipart upload is not aborted\n")
+	}
+
+	server.aborted = false
+	w = writerAPI.NewObjectWriter("bucket", "object", opts)
+	w.Write(data)
+	var reason = errors.New("source broken")
+	if err = w.CloseWithError(reason); err != nil {
+		t.Fatalf("ObjectWriter.CloseWithError: except: nil, but got: %s\n", err)
+	}
+	if !server.aborted {
+		t.Fatalf("ObjectWriter.CloseWithError: the multipart upload is not aborted\n")
+	}
+}
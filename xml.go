@@ -2,6 +2,8 @@ package oss
 
 import (
 	"encoding/xml"
+	"fmt"
+	"net/url"
 	"time"
 )
 
@@ -55,17 +57,99 @@ type Content struct {
 
 // ListBucketResult defined list bucket result
 type ListBucketResult struct {
-	XMLName        xml.Name `xml:"ListBucketResult"`
-	Contents       []Content
-	CommonPrefixes string
+	XMLName  xml.Name `xml:"ListBucketResult"`
+	Contents []Content
+	// the keys grouped by delimiter, e.g.: "fun/movie/"
+	CommonPrefixes []string `xml:"CommonPrefixes>Prefix"`
 	Delimiter      string
 	IsTruncated    bool
 	Marker         string
-	MaxKeys        string
-	Name           string
-	Owner          Owner
-	Prefix         string
-	EncodingType   string `xml:"encoding-type"`
+	// the marker of next page, only returned when IsTruncated is true
+	NextMarker string
+	MaxKeys    string
+	Name       string
+	Owner      Owner
+	Prefix     string
+	// set it to "url" to request the keys url encoded, they are decoded by ListBucket
+	EncodingType string
+}
+
+// decodeKeys decode the url encoded keys when the server returns EncodingType url,
+// the request fields not echoed by the server are never decoded.
+func (result *ListBucketResult) decodeKeys() error {
+	if result.EncodingType != "url" {
+		return nil
+	}
+	var fields = []*string{&result.Delimiter, &result.Marker, &result.NextMarker, &result.Prefix}
+	return decodeListKeys(fields, result.Contents, result.CommonPrefixes)
+}
+
+// ListBucketV2Result defined list bucket result of ListBucketV2 (list-type=2)
+type ListBucketV2Result struct {
+	XMLName  xml.Name `xml:"ListBucketResult"`
+	Contents []Content
+	// the keys grouped by delimiter, e.g.: "fun/movie/"
+	CommonPrefixes []string `xml:"CommonPrefixes>Prefix"`
+	Delimiter      string
+	IsTruncated    bool
+	// list the objects after StartAfter, it is ignored when ContinuationToken is set
+	StartAfter string
+	// the token of this page, copy NextContinuationToken into it to request the next page
+	ContinuationToken string
+	// the token of next page, only returned when IsTruncated is true
+	NextContinuationToken string
+	// the number of keys and common prefixes in this page
+	KeyCount int
+	MaxKeys  string
+	Name     string
+	Prefix   string
+	// set it to "url" to request the keys url encoded, they are decoded by ListBucketV2
+	EncodingType string
+	// return the owner of objects or not, it is only a request parameter
+	FetchOwner bool `xml:"-"`
+}
+
+// decodeKeys decode the url encoded keys when the server returns EncodingType url,
+// the request fields not echoed by the server are never decoded.
+func (result *ListBucketV2Result) decodeKeys() error {
+	if result.EncodingType != "url" {
+		return nil
+	}
+	var fields = []*string{&result.Delimiter, &result.StartAfter, &result.Prefix}
+	return decodeListKeys(fields, result.Contents, result.CommonPrefixes)
+}
+
+// clearEchoFields clear the request fields the server echoes before the response is unmarshaled,
+// so only the echoed ones are decoded. The returned func restore the fields not echoed to the request values.
+func clearEchoFields(fields ...*string) func() {
+	var values = make([]string, len(fields))
+	for i, field := range fields {
+		values[i], *field = *field, ""
+	}
+	return func() {
+		for i, field := range fields {
+			if *field == "" {
+				*field = values[i]
+			}
+		}
+	}
+}
+
+// decodeListKeys decode the fields, the keys of contents and the common prefixes in place
+func decodeListKeys(fields []*string, contents []Content, commonPrefixes []string) error {
+	for i := range contents {
+		fields = append(fields, &contents[i].Key)
+	}
+	for i := range commonPrefixes {
+		fields = append(fields, &commonPrefixes[i])
+	}
+	var err error
+	for _, field := range fields {
+		if *field, err = url.QueryUnescape(*field); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // AccessControlPolicy defined access control policy
@@ -152,6 +236,19 @@ type DeleteXML struct {
 type DeleteResult struct {
 	XMLName xml.Name `xml:"DeleteResult"`
 	Objects []string `xml:"Deleted>Key"`
+	// the keys failed to delete
+	Errors []DeleteError `xml:"Error"`
+}
+
+// DeleteError defined the failure of a key in delete result
+type DeleteError struct {
+	Key     string
+	Code    string
+	Message string
+}
+
+func (e DeleteError) Error() string {
+	return fmt.Sprintf("oss: delete %s failed: %s %s", e.Key, e.Code, e.Message)
 }
 
 // InitiateMultipartUploadResult defined initiate multipart upload result
@@ -225,7 +322,7 @@ type ListPartsResult struct {
 	NextPartNumberMarker int
 	MaxParts             int
 	IsTruncated          bool
-	Parts                []Part
+	Parts                []Part `xml:"Part"`
 }
 
 // CORSRule defined cors rule
@@ -240,6 +337,6 @@ type CORSRule struct {
 
 // CORSConfiguration defined cors configuration
 type CORSConfiguration struct {
-	XMLName xml.Name `xml:"CORSConfiguration"`
-	Rules   []CORSRule
+	XMLName xml.Name   `xml:"CORSConfiguration"`
+	Rules   []CORSRule `xml:"CORSRule"`
 }
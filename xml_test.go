@@ -0,0 +1,58 @@
+package oss
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetBucketCORS(t *testing.T) {
+	// the rules are the CORSRule elements of CORSConfiguration
+	var corsXML = `<?xml version="1.0" encoding="UTF-8"?>
+<CORSConfiguration>
+  <CORSRule>
+    <AllowedOrigin>*</AllowedOrigin>
+    <AllowedMethod>PUT</AllowedMethod>
+    <AllowedMethod>GET</AllowedMethod>
+    <AllowedHeader>Authorization</AllowedHeader>
+  </CORSRule>
+  <CORSRule>
+    <AllowedOrigin>http://www.a.com</AllowedOrigin>
+    <AllowedMethod>GET</AllowedMethod>
+    <ExposeHeader>x-oss-test</ExposeHeader>
+    <MaxAgeSeconds>100</MaxAgeSeconds>
+  </CORSRule>
+</CORSConfiguration>`
+	var putBody string
+	var corsAPI, closeServer = newRetryTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == "PUT" {
+			var data, _ = ioutil.ReadAll(req.Body)
+			putBody = string(data)
+			return
+		}
+		fmt.Fprint(w, corsXML)
+	})
+	defer closeServer()
+
+	var config CORSConfiguration
+	if err := corsAPI.GetBucketCORS("bucket", &config); err != nil {
+		t.Fatalf("GetBucketCORS: except: nil, but got: %s\n", err)
+	}
+	if len(config.Rules) != 2 {
+		t.Fatalf("GetBucketCORS: except: %d rules, but got: %d\n", 2, len(config.Rules))
+	}
+	var rule = config.Rules[1]
+	if strings.Join(config.Rules[0].AllowedMethod, ",") != "PUT,GET" || rule.AllowedOrigin[0] != "http://www.a.com" ||
+		rule.ExposeHeader[0] != "x-oss-test" || rule.MaxAgeSeconds != 100 {
+		t.Fatalf("GetBucketCORS: got unexcept rules: %+v\n", config.Rules)
+	}
+
+	if err := corsAPI.PutBucketCORS("bucket", config); err != nil {
+		t.Fatalf("PutBucketCORS: except: nil, but got: %s\n", err)
+	}
+	if strings.Count(putBody, "<CORSRule>") != 2 || strings.Contains(putBody, "<Rules>") {
+		t.Fatalf("PutBucketCORS: except: %d CORSRule, but got: %s\n", 2, putBody)
+	}
+}